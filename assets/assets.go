@@ -23,14 +23,10 @@ var LevelFont = mustLoadFontFace("fonts/score.ttf")
 var MeteorSprites = mustLoadImages("images/meteors/*.png")
 var MeteorSpritesSmall = mustLoadImages("images/meteors-small/*.png")
 var LaserSprite = mustLoadImage("images/laser.png")
-var ExplosionSprite = mustLoadImage("images/explosion.png")
-var ExplosionSmallSprite = mustLoadImage("images/explosion-small.png")
 var Explosion = createExplosion()
 var ThrustSound = mustLoadOggVorbis("audio/thrust.ogg")
 var ExhaustSprite = mustLoadImage("images/fire.png")
-var LaserOneSound = mustLoadOggVorbis("audio/fire.ogg")
-var LaserTwoSound = mustLoadOggVorbis("audio/fire.ogg")
-var LaserThirdSound = mustLoadOggVorbis("audio/fire.ogg")
+var LaserSound = mustLoadOggVorbis("audio/fire.ogg")
 var ExplosionSound = mustLoadOggVorbis("audio/explosion.ogg")
 var BeatOneSound = mustLoadOggVorbis("audio/beat1.ogg")
 var BeatTwoSound = mustLoadOggVorbis("audio/beat2.ogg")
@@ -0,0 +1,35 @@
+package goasteroids
+
+import (
+	"go-asteroids/assets"
+	"go-asteroids/sim"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// SpriteAnimation is the images of an animation, one for each of the frames
+// a sim.Animation counts through. The timing lives in the sim, so that
+// whatever waits on an animation, such as removing an exploded meteor, happens
+// on the same tick in a replay.
+type SpriteAnimation []*ebiten.Image
+
+// Frame returns the image of the frame a has reached.
+func (s SpriteAnimation) Frame(a *sim.Animation) *ebiten.Image {
+	return s[min(a.Frame(), len(s)-1)]
+}
+
+var explosionAnimation = SpriteAnimation(assets.Explosion)
+
+// drawExplosion draws the frame the explosion a has reached, centred on
+// center and scaled to the width of what is exploding.
+func drawExplosion(screen *ebiten.Image, a *sim.Animation, center Vector, width float64) {
+	sprite := explosionAnimation.Frame(a)
+	halfW, halfH := HalfOfTheImage(sprite)
+	scale := width / float64(sprite.Bounds().Dx())
+
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(-halfW, -halfH)
+	op.GeoM.Scale(scale, scale)
+	op.GeoM.Translate(center.X, center.Y)
+	screen.DrawImage(sprite, op)
+}
@@ -0,0 +1,293 @@
+package goasteroids
+
+import (
+	"bytes"
+	"errors"
+	"go-asteroids/assets"
+	"io"
+	"log"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2/audio"
+	"github.com/hajimehoshi/ebiten/v2/audio/vorbis"
+)
+
+const sampleRate = 48000
+
+// SoundID names a sound the game can play.
+type SoundID int
+
+const (
+	SoundThrust SoundID = iota
+	SoundLaser
+	SoundExplosion
+	SoundBeatOne
+	SoundBeatTwo
+	SoundShieldUp
+	SoundAlien
+	SoundAlienLaser
+	soundCount
+)
+
+// Bus is a group of sounds whose volume is set together.
+type Bus int
+
+const (
+	// BusSFX carries the sounds of the game world.
+	BusSFX Bus = iota
+	// BusMusic carries the music and the heartbeat.
+	BusMusic
+	// BusUI carries the sounds of the menus.
+	BusUI
+	busCount
+)
+
+// soundDef describes how a sound is played. A sound may play on up to
+// maxVoices voices at once; when they are all busy the one started longest
+// ago is restarted. A sound is not started again within cooldown of the last
+// time. Looped sounds play until stopped and are left alone when asked to
+// play while playing.
+type soundDef struct {
+	stream    *vorbis.Stream
+	bus       Bus
+	volume    float64
+	loop      bool
+	maxVoices int
+	cooldown  time.Duration
+}
+
+var soundDefs = [soundCount]soundDef{
+	SoundThrust:     {stream: assets.ThrustSound, bus: BusSFX, volume: 1, loop: true, maxVoices: 1},
+	SoundLaser:      {stream: assets.LaserSound, bus: BusSFX, volume: 1, maxVoices: 3},
+	SoundExplosion:  {stream: assets.ExplosionSound, bus: BusSFX, volume: 1, maxVoices: 4, cooldown: 50 * time.Millisecond},
+	SoundBeatOne:    {stream: assets.BeatOneSound, bus: BusMusic, volume: 1, maxVoices: 1},
+	SoundBeatTwo:    {stream: assets.BeatTwoSound, bus: BusMusic, volume: 1, maxVoices: 1},
+	SoundShieldUp:   {stream: assets.ShieldSound, bus: BusSFX, volume: 1, maxVoices: 1},
+	SoundAlien:      {stream: assets.AlienSound, bus: BusSFX, volume: 0.5, loop: true, maxVoices: 1},
+	SoundAlienLaser: {stream: assets.AlienLaserSound, bus: BusSFX, volume: 1, maxVoices: 3, cooldown: 50 * time.Millisecond},
+}
+
+// Placement is where a sound is heard from. Pan runs from -1 for the left
+// speaker to 1 for the right, and Gain scales the volume of the sound.
+type Placement struct {
+	Pan  float64
+	Gain float64
+}
+
+// centered is the placement of sounds that are not in the game world.
+var centered = Placement{Gain: 1}
+
+type voice struct {
+	player  *audio.Player
+	pan     *panStream
+	gain    float64
+	started time.Time
+}
+
+type sound struct {
+	def       soundDef
+	pcm       []byte
+	voices    []*voice
+	lastStart time.Time
+}
+
+type bus struct {
+	volume float64
+	muted  bool
+}
+
+// AudioManager owns the audio context and every sound played through it. It
+// is created once for the whole game. Each voice reads its own copy of the
+// decoded sound, so any number of them can play at the same time.
+type AudioManager struct {
+	context *audio.Context
+	sounds  [soundCount]sound
+	buses   [busCount]bus
+	// tracks are the music, of which music is playing or fading in. The
+	// stem levels move towards their targets a little every tick.
+	tracks      [musicTrackCount]*trackPlayer
+	music       MusicTrack
+	stemLevels  [stemCount]float64
+	stemTargets [stemCount]float64
+}
+
+// NewAudioManager creates the audio context, decodes every sound and
+// synthesises the music up front.
+func NewAudioManager() (*AudioManager, error) {
+	a := &AudioManager{context: audio.NewContext(sampleRate)}
+	for i := range a.buses {
+		a.buses[i].volume = 1
+	}
+
+	for id, def := range soundDefs {
+		pcm, err := io.ReadAll(def.stream)
+		if err != nil {
+			return nil, err
+		}
+		a.sounds[id] = sound{def: def, pcm: pcm}
+	}
+
+	if err := a.loadMusic(); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Play starts the sound in the middle of the speakers.
+func (a *AudioManager) Play(id SoundID) {
+	a.PlayAt(id, centered)
+}
+
+// PlayAt starts the sound at p on a free voice, or on the oldest one if they
+// are all busy. A looped sound that is already playing is moved to p.
+func (a *AudioManager) PlayAt(id SoundID, p Placement) {
+	s := &a.sounds[id]
+	now := time.Now()
+	if s.def.loop && a.IsPlaying(id) {
+		a.Move(id, p)
+		return
+	}
+	if now.Sub(s.lastStart) < s.def.cooldown {
+		return
+	}
+
+	v := a.voice(s)
+	if v == nil {
+		return
+	}
+	if err := v.player.Rewind(); err != nil {
+		log.Println("Error rewinding sound:", err)
+	}
+	a.place(s, v, p)
+	v.player.Play()
+	v.started = now
+	s.lastStart = now
+}
+
+// voice returns the voice to play s on, making a new one while there are
+// fewer than the sound allows.
+func (a *AudioManager) voice(s *sound) *voice {
+	var oldest *voice
+	for _, v := range s.voices {
+		if !v.player.IsPlaying() {
+			return v
+		}
+		if oldest == nil || v.started.Before(oldest.started) {
+			oldest = v
+		}
+	}
+
+	if len(s.voices) >= s.def.maxVoices {
+		return oldest
+	}
+
+	var src io.ReadSeeker = bytes.NewReader(s.pcm)
+	if s.def.loop {
+		src = audio.NewInfiniteLoop(src, int64(len(s.pcm)))
+	}
+	pan := newPanStream(src)
+	p, err := a.context.NewPlayer(pan)
+	if err != nil {
+		log.Println("Error creating sound player:", err)
+		return nil
+	}
+	v := &voice{player: p, pan: pan}
+	s.voices = append(s.voices, v)
+	return v
+}
+
+// Move places every playing voice of the sound at p, for sounds that follow
+// something around.
+func (a *AudioManager) Move(id SoundID, p Placement) {
+	s := &a.sounds[id]
+	for _, v := range s.voices {
+		if v.player.IsPlaying() {
+			a.place(s, v, p)
+		}
+	}
+}
+
+func (a *AudioManager) place(s *sound, v *voice, p Placement) {
+	v.pan.setPan(p.Pan)
+	v.gain = p.Gain
+	v.player.SetVolume(a.volume(s.def) * v.gain)
+}
+
+// Stop pauses every voice of the sound.
+func (a *AudioManager) Stop(id SoundID) {
+	for _, v := range a.sounds[id].voices {
+		v.player.Pause()
+	}
+}
+
+// IsPlaying reports whether any voice of the sound is playing.
+func (a *AudioManager) IsPlaying(id SoundID) bool {
+	for _, v := range a.sounds[id].voices {
+		if v.player.IsPlaying() {
+			return true
+		}
+	}
+	return false
+}
+
+// SetBusVolume scales every sound on the bus, including those playing.
+func (a *AudioManager) SetBusVolume(b Bus, volume float64) {
+	if a.buses[b].volume == volume {
+		return
+	}
+	a.buses[b].volume = volume
+	a.updateBus(b)
+}
+
+// SetBusMuted silences the bus without forgetting its volume.
+func (a *AudioManager) SetBusMuted(b Bus, muted bool) {
+	if a.buses[b].muted == muted {
+		return
+	}
+	a.buses[b].muted = muted
+	a.updateBus(b)
+}
+
+func (a *AudioManager) updateBus(b Bus) {
+	for i := range a.sounds {
+		s := &a.sounds[i]
+		if s.def.bus != b {
+			continue
+		}
+		for _, v := range s.voices {
+			v.player.SetVolume(a.volume(s.def) * v.gain)
+		}
+	}
+}
+
+func (a *AudioManager) volume(def soundDef) float64 {
+	return def.volume * a.busVolume(def.bus)
+}
+
+func (a *AudioManager) busVolume(b Bus) float64 {
+	if a.buses[b].muted {
+		return 0
+	}
+	return a.buses[b].volume
+}
+
+// Close releases every voice and the music.
+func (a *AudioManager) Close() error {
+	var errs []error
+	for i := range a.sounds {
+		for _, v := range a.sounds[i].voices {
+			errs = append(errs, v.player.Close())
+		}
+		a.sounds[i].voices = nil
+	}
+	for i, t := range a.tracks {
+		if t == nil {
+			continue
+		}
+		for _, p := range t.stems {
+			errs = append(errs, p.Close())
+		}
+		a.tracks[i] = nil
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,252 @@
+package goasteroids
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const bindingsVersion = 1
+
+// Binding is a single keyboard key or standard layout gamepad button.
+type Binding struct {
+	Gamepad bool
+	Key     ebiten.Key
+	Button  ebiten.StandardGamepadButton
+}
+
+func KeyBinding(k ebiten.Key) Binding {
+	return Binding{Key: k}
+}
+
+func ButtonBinding(b ebiten.StandardGamepadButton) Binding {
+	return Binding{Gamepad: true, Button: b}
+}
+
+var gamepadButtonNames = map[ebiten.StandardGamepadButton]string{
+	ebiten.StandardGamepadButtonRightBottom:      "RightBottom",
+	ebiten.StandardGamepadButtonRightRight:       "RightRight",
+	ebiten.StandardGamepadButtonRightLeft:        "RightLeft",
+	ebiten.StandardGamepadButtonRightTop:         "RightTop",
+	ebiten.StandardGamepadButtonFrontTopLeft:     "FrontTopLeft",
+	ebiten.StandardGamepadButtonFrontTopRight:    "FrontTopRight",
+	ebiten.StandardGamepadButtonFrontBottomLeft:  "FrontBottomLeft",
+	ebiten.StandardGamepadButtonFrontBottomRight: "FrontBottomRight",
+	ebiten.StandardGamepadButtonCenterLeft:       "CenterLeft",
+	ebiten.StandardGamepadButtonCenterRight:      "CenterRight",
+	ebiten.StandardGamepadButtonLeftStick:        "LeftStick",
+	ebiten.StandardGamepadButtonRightStick:       "RightStick",
+	ebiten.StandardGamepadButtonLeftTop:          "LeftTop",
+	ebiten.StandardGamepadButtonLeftBottom:       "LeftBottom",
+	ebiten.StandardGamepadButtonLeftLeft:         "LeftLeft",
+	ebiten.StandardGamepadButtonLeftRight:        "LeftRight",
+	ebiten.StandardGamepadButtonCenterCenter:     "CenterCenter",
+}
+
+// gamepadButtonLabels name the buttons after their position on an Xbox style
+// controller, which is what the standard layout is modelled on.
+var gamepadButtonLabels = map[ebiten.StandardGamepadButton]string{
+	ebiten.StandardGamepadButtonRightBottom:      "PAD A",
+	ebiten.StandardGamepadButtonRightRight:       "PAD B",
+	ebiten.StandardGamepadButtonRightLeft:        "PAD X",
+	ebiten.StandardGamepadButtonRightTop:         "PAD Y",
+	ebiten.StandardGamepadButtonFrontTopLeft:     "PAD LB",
+	ebiten.StandardGamepadButtonFrontTopRight:    "PAD RB",
+	ebiten.StandardGamepadButtonFrontBottomLeft:  "PAD LT",
+	ebiten.StandardGamepadButtonFrontBottomRight: "PAD RT",
+	ebiten.StandardGamepadButtonCenterLeft:       "PAD BACK",
+	ebiten.StandardGamepadButtonCenterRight:      "PAD START",
+	ebiten.StandardGamepadButtonLeftStick:        "PAD L3",
+	ebiten.StandardGamepadButtonRightStick:       "PAD R3",
+	ebiten.StandardGamepadButtonLeftTop:          "DPAD UP",
+	ebiten.StandardGamepadButtonLeftBottom:       "DPAD DOWN",
+	ebiten.StandardGamepadButtonLeftLeft:         "DPAD LEFT",
+	ebiten.StandardGamepadButtonLeftRight:        "DPAD RIGHT",
+	ebiten.StandardGamepadButtonCenterCenter:     "PAD GUIDE",
+}
+
+// Label is the text shown for the binding in menus.
+func (b Binding) Label() string {
+	if b.Gamepad {
+		return gamepadButtonLabels[b.Button]
+	}
+	return strings.ToUpper(b.Key.String())
+}
+
+func (b Binding) MarshalText() ([]byte, error) {
+	if b.Gamepad {
+		name, ok := gamepadButtonNames[b.Button]
+		if !ok {
+			return nil, fmt.Errorf("unknown gamepad button %d", b.Button)
+		}
+		return []byte("pad:" + name), nil
+	}
+
+	key, err := b.Key.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte("key:"), key...), nil
+}
+
+func (b *Binding) UnmarshalText(text []byte) error {
+	device, name, ok := strings.Cut(string(text), ":")
+	if !ok {
+		return fmt.Errorf("binding %q is missing a device prefix", text)
+	}
+
+	switch device {
+	case "key":
+		var k ebiten.Key
+		if err := k.UnmarshalText([]byte(name)); err != nil {
+			return err
+		}
+		*b = KeyBinding(k)
+		return nil
+	case "pad":
+		for button, n := range gamepadButtonNames {
+			if n == name {
+				*b = ButtonBinding(button)
+				return nil
+			}
+		}
+		return fmt.Errorf("unknown gamepad button %q", name)
+	default:
+		return fmt.Errorf("unknown device %q in binding %q", device, text)
+	}
+}
+
+// Bindings maps every action to the keys and buttons that trigger it.
+type Bindings map[Action][]Binding
+
+func DefaultBindings() Bindings {
+	// Thrust has no default gamepad button: the right trigger is read as an
+	// analog throttle instead.
+	return Bindings{
+		ActionRotateLeft:  {KeyBinding(ebiten.KeyLeft), ButtonBinding(ebiten.StandardGamepadButtonLeftLeft)},
+		ActionRotateRight: {KeyBinding(ebiten.KeyRight), ButtonBinding(ebiten.StandardGamepadButtonLeftRight)},
+		ActionThrust:      {KeyBinding(ebiten.KeyUp)},
+		ActionReverse:     {KeyBinding(ebiten.KeyDown), ButtonBinding(ebiten.StandardGamepadButtonFrontBottomLeft)},
+		ActionFire:        {KeyBinding(ebiten.KeySpace), ButtonBinding(ebiten.StandardGamepadButtonRightBottom)},
+		ActionShield:      {KeyBinding(ebiten.KeyS), ButtonBinding(ebiten.StandardGamepadButtonRightRight)},
+		ActionHyperSpace:  {KeyBinding(ebiten.KeyH), ButtonBinding(ebiten.StandardGamepadButtonRightTop)},
+		ActionPause:       {KeyBinding(ebiten.KeyEscape), KeyBinding(ebiten.KeyP), ButtonBinding(ebiten.StandardGamepadButtonCenterRight)},
+		ActionConfirm:     {KeyBinding(ebiten.KeySpace), KeyBinding(ebiten.KeyEnter), ButtonBinding(ebiten.StandardGamepadButtonRightBottom)},
+		ActionBack:        {KeyBinding(ebiten.KeyEscape), KeyBinding(ebiten.KeyBackspace), ButtonBinding(ebiten.StandardGamepadButtonRightRight)},
+		ActionUp:          {KeyBinding(ebiten.KeyUp), ButtonBinding(ebiten.StandardGamepadButtonLeftTop)},
+		ActionDown:        {KeyBinding(ebiten.KeyDown), ButtonBinding(ebiten.StandardGamepadButtonLeftBottom)},
+		ActionLeft:        {KeyBinding(ebiten.KeyLeft), ButtonBinding(ebiten.StandardGamepadButtonLeftLeft)},
+		ActionRight:       {KeyBinding(ebiten.KeyRight), ButtonBinding(ebiten.StandardGamepadButtonLeftRight)},
+		ActionNewGame:     {KeyBinding(ebiten.KeyN), ButtonBinding(ebiten.StandardGamepadButtonFrontTopLeft)},
+		ActionControls:    {KeyBinding(ebiten.KeyC), ButtonBinding(ebiten.StandardGamepadButtonRightTop)},
+		ActionOptions:     {KeyBinding(ebiten.KeyO), ButtonBinding(ebiten.StandardGamepadButtonFrontTopRight)},
+		ActionReplay:      {KeyBinding(ebiten.KeyR), ButtonBinding(ebiten.StandardGamepadButtonRightLeft)},
+		ActionQuit:        {KeyBinding(ebiten.KeyQ), ButtonBinding(ebiten.StandardGamepadButtonCenterLeft)},
+	}
+}
+
+func (b Bindings) Clone() Bindings {
+	c := make(Bindings, len(b))
+	for a, list := range b {
+		c[a] = slices.Clone(list)
+	}
+	return c
+}
+
+// Conflict returns the action that already uses binding in a context a is
+// also checked in. Ship controls and menu controls are never checked at the
+// same time, so they may share keys, but quit is checked during play too and
+// may not share one with the ship.
+func (b Bindings) Conflict(a Action, binding Binding) (Action, bool) {
+	for other := range actionCount {
+		if other == a || !a.sharesContext(other) {
+			continue
+		}
+		if slices.Contains(b[other], binding) {
+			return other, true
+		}
+	}
+	return 0, false
+}
+
+// Rebind replaces the bindings of a for the device binding belongs to,
+// leaving bindings for the other device untouched.
+func (b Bindings) Rebind(a Action, binding Binding) {
+	list := slices.DeleteFunc(b[a], func(existing Binding) bool {
+		return existing.Gamepad == binding.Gamepad
+	})
+	b[a] = append(list, binding)
+}
+
+type bindingsFile struct {
+	Version  int                  `json:"version"`
+	Bindings map[string][]Binding `json:"bindings"`
+}
+
+func getBindingsFile() (string, error) {
+	return getDataFile("controls.json")
+}
+
+// loadBindings reads the saved controls. Actions missing from the file keep
+// their default bindings so that new actions work with old files.
+func loadBindings() (Bindings, error) {
+	bindings := DefaultBindings()
+
+	path, err := getBindingsFile()
+	if err != nil {
+		return bindings, err
+	}
+
+	contents, err := loadSaveFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return bindings, nil
+	}
+	if err != nil {
+		return bindings, fmt.Errorf("failed to read controls file: %w", err)
+	}
+
+	var f bindingsFile
+	if err := json.Unmarshal(contents, &f); err != nil {
+		return bindings, fmt.Errorf("failed to parse controls file: %w", err)
+	}
+	if f.Version != bindingsVersion {
+		return bindings, fmt.Errorf("unsupported controls file version %d", f.Version)
+	}
+
+	for a := range actionCount {
+		if list, ok := f.Bindings[a.Name()]; ok {
+			bindings[a] = list
+		}
+	}
+	return bindings, nil
+}
+
+func saveBindings(bindings Bindings) error {
+	path, err := getBindingsFile()
+	if err != nil {
+		return err
+	}
+
+	f := bindingsFile{
+		Version:  bindingsVersion,
+		Bindings: make(map[string][]Binding, len(bindings)),
+	}
+	for a, list := range bindings {
+		f.Bindings[a.Name()] = list
+	}
+
+	contents, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode controls: %w", err)
+	}
+
+	if err := writeSaveFile(path, contents); err != nil {
+		return fmt.Errorf("failed to write controls file: %w", err)
+	}
+	return nil
+}
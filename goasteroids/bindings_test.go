@@ -0,0 +1,34 @@
+package goasteroids
+
+import (
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestConflict(t *testing.T) {
+	b := DefaultBindings()
+	for _, c := range []struct {
+		name     string
+		action   Action
+		binding  Binding
+		conflict Action
+		ok       bool
+	}{
+		{"quit on the fire key", ActionQuit, KeyBinding(ebiten.KeySpace), ActionFire, true},
+		{"quit on the thrust key", ActionQuit, KeyBinding(ebiten.KeyUp), ActionThrust, true},
+		{"fire on the quit key", ActionFire, KeyBinding(ebiten.KeyQ), ActionQuit, true},
+		{"menu actions together", ActionReplay, KeyBinding(ebiten.KeyN), ActionNewGame, true},
+		{"ship and menu apart", ActionShield, KeyBinding(ebiten.KeyBackspace), 0, false},
+		{"menu and ship apart", ActionControls, KeyBinding(ebiten.KeyH), 0, false},
+		{"unused key", ActionQuit, KeyBinding(ebiten.KeyF12), 0, false},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			conflict, ok := b.Conflict(c.action, c.binding)
+			if ok != c.ok || ok && conflict != c.conflict {
+				t.Errorf("Conflict(%s, %s) = %s, %v, want %s, %v",
+					c.action.Name(), c.binding.Label(), conflict.Name(), ok, c.conflict.Name(), c.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package goasteroids
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	// traumaDecay is how much trauma drains away each tick, so that a full
+	// shake settles in three quarters of a second.
+	traumaDecay = 1.0 / 45
+	// maxShakeOffset and maxShakeAngle are how far the playfield moves and
+	// turns at full trauma.
+	maxShakeOffset = 16
+	maxShakeAngle  = 0.05
+	// zoomDecay is the part of a zoom kept each tick as it settles back, and
+	// maxZoom how far in a full zoom pushes.
+	zoomDecay = 0.92
+	maxZoom   = 0.06
+)
+
+// Camera sits between the scenes and the view and moves the playfield to give
+// impacts some weight. Trauma builds up from what happens in the game and
+// shakes the playfield by its square, so that small knocks barely register
+// and big ones rattle, easing off as it drains away. A hit-stop holds the game
+// still for a few ticks, and a zoom pushes in on a point and settles back.
+//
+// Every effect is scaled by the screen shake setting, and none happens at all
+// when it is zero. The HUD is drawn after the camera and never moves.
+type Camera struct {
+	intensity float64
+	trauma    float64
+	hitStop   int
+	zoom      float64
+	focus     Vector
+	tick      int
+	image     *ebiten.Image
+}
+
+// AddTrauma shakes the camera harder, up to a trauma of 1.
+func (c *Camera) AddTrauma(amount float64) {
+	c.trauma = min(c.trauma+amount, 1)
+}
+
+// HitStop holds the game still for ticks at full intensity, fewer at less.
+func (c *Camera) HitStop(ticks int) {
+	c.hitStop = max(c.hitStop, int(math.Round(float64(ticks)*c.intensity)))
+}
+
+// Zoom pushes in on focus, in playfield coordinates, by amount from 0 to 1.
+func (c *Camera) Zoom(focus Vector, amount float64) {
+	c.focus = focus
+	c.zoom = max(c.zoom, min(amount, 1))
+}
+
+// Held reports whether the game should skip this tick for a hit-stop, using
+// up a tick of it if so.
+func (c *Camera) Held() bool {
+	if c.hitStop == 0 {
+		return false
+	}
+	c.hitStop--
+	return true
+}
+
+// Settle drops any shake, hit-stop and zoom still to come, so that the end of
+// one level or game never shakes, holds up or zooms into the start of the next.
+func (c *Camera) Settle() {
+	c.trauma = 0
+	c.hitStop = 0
+	c.zoom = 0
+}
+
+// Update lets the shake and zoom settle by a tick, at the intensity the
+// screen shake setting asks for.
+func (c *Camera) Update(intensity float64) {
+	c.intensity = intensity
+	c.tick++
+	c.trauma = max(0, c.trauma-traumaDecay)
+	c.zoom *= zoomDecay
+	if c.zoom < 0.001 {
+		c.zoom = 0
+	}
+}
+
+// apply returns the playfield as the camera sees it. While the camera is
+// still that is playfield itself.
+func (c *Camera) apply(playfield *ebiten.Image) *ebiten.Image {
+	shake := c.trauma * c.trauma * c.intensity
+	zoom := c.zoom * c.intensity
+	if shake == 0 && zoom == 0 {
+		return playfield
+	}
+
+	if c.image == nil {
+		c.image = ebiten.NewImage(ScreenWidth, ScreenHeight)
+	}
+	c.image.Clear()
+
+	op := &ebiten.DrawImageOptions{Filter: ebiten.FilterLinear}
+	scale := 1 + zoom*maxZoom
+	op.GeoM.Translate(-c.focus.X, -c.focus.Y)
+	op.GeoM.Scale(scale, scale)
+	op.GeoM.Translate(c.focus.X, c.focus.Y)
+
+	op.GeoM.Translate(-ScreenWidth/2, -ScreenHeight/2)
+	op.GeoM.Rotate(maxShakeAngle * shake * shakeNoise(c.tick, 0))
+	op.GeoM.Translate(
+		ScreenWidth/2+maxShakeOffset*shake*shakeNoise(c.tick, 1),
+		ScreenHeight/2+maxShakeOffset*shake*shakeNoise(c.tick, 2),
+	)
+	c.image.DrawImage(playfield, op)
+	return c.image
+}
+
+// shakeNoise is smooth noise between -1 and 1 over ticks, following a
+// different path for every seed, so that the shake judders without jumping
+// from frame to frame.
+func shakeNoise(tick int, seed float64) float64 {
+	t := float64(tick)
+	return (math.Sin(t*0.9+seed*2.1) + math.Sin(t*1.7+seed*4.7)/2) / 1.5
+}
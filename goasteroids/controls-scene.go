@@ -0,0 +1,173 @@
+package goasteroids
+
+import (
+	"fmt"
+	"go-asteroids/assets"
+	"image/color"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+)
+
+const (
+	controlsRowReset = int(actionCount) + iota
+	controlsRowBack
+	controlsRowCount
+)
+
+var selectedColor = color.RGBA{R: 0xff, G: 0xd7, B: 0x00, A: 0xff}
+
+// ControlsScene is pushed over the title and lets the player rebind every
+// action to keys or gamepad buttons. The bindings are saved when it is popped.
+type ControlsScene struct {
+	bindings  Bindings
+	selected  int
+	capturing bool
+	message   string
+	stars     []*Star
+	keys      []ebiten.Key
+	buttons   []ebiten.StandardGamepadButton
+	padIDs    []ebiten.GamepadID
+}
+
+func NewControlsScene(bindings Bindings) *ControlsScene {
+	return &ControlsScene{
+		bindings: bindings,
+		stars:    GenerateStars(newRand(), numberOfStars),
+	}
+}
+
+func (c *ControlsScene) Update(state *State) error {
+	if c.capturing {
+		c.capture()
+		return nil
+	}
+
+	if state.Input.IsJustPressed(ActionUp) {
+		c.selected = (c.selected + controlsRowCount - 1) % controlsRowCount
+	}
+
+	if state.Input.IsJustPressed(ActionDown) {
+		c.selected = (c.selected + 1) % controlsRowCount
+	}
+
+	if state.Input.IsJustPressed(ActionBack) {
+		c.leave(state)
+		return nil
+	}
+
+	if state.Input.IsJustPressed(ActionConfirm) {
+		switch c.selected {
+		case controlsRowReset:
+			c.bindings = DefaultBindings()
+			c.message = "DEFAULT CONTROLS RESTORED"
+		case controlsRowBack:
+			c.leave(state)
+		default:
+			c.capturing = true
+			c.message = "PRESS A KEY OR BUTTON, ESCAPE TO CANCEL"
+		}
+	}
+
+	return nil
+}
+
+// capture waits for the next key or gamepad button and binds it to the
+// selected action unless another action in the same context already uses it.
+func (c *ControlsScene) capture() {
+	c.keys = inpututil.AppendJustPressedKeys(c.keys[:0])
+	c.buttons = c.buttons[:0]
+	c.padIDs = ebiten.AppendGamepadIDs(c.padIDs[:0])
+	for _, id := range c.padIDs {
+		if ebiten.IsStandardGamepadLayoutAvailable(id) {
+			c.buttons = inpututil.AppendJustPressedStandardGamepadButtons(id, c.buttons)
+		}
+	}
+
+	if slices.Contains(c.keys, ebiten.KeyEscape) {
+		c.capturing = false
+		c.message = ""
+		return
+	}
+
+	var b Binding
+	switch {
+	case len(c.keys) > 0:
+		b = KeyBinding(c.keys[0])
+	case len(c.buttons) > 0:
+		b = ButtonBinding(c.buttons[0])
+	default:
+		return
+	}
+
+	c.capturing = false
+	action := Action(c.selected)
+	if other, ok := c.bindings.Conflict(action, b); ok {
+		c.message = fmt.Sprintf("%s IS ALREADY USED BY %s", b.Label(), other.Label())
+		return
+	}
+
+	c.bindings.Rebind(action, b)
+	c.message = ""
+}
+
+func (c *ControlsScene) leave(state *State) {
+	if err := c.OnShutdown(); err != nil {
+		log.Println("Error saving controls:", err)
+	}
+	state.Input.SetBindings(c.bindings)
+	state.SceneManager.Pop()
+}
+
+// OnShutdown saves the bindings, as leaving the scene would.
+func (c *ControlsScene) OnShutdown() error {
+	return saveBindings(c.bindings)
+}
+
+func (c *ControlsScene) Draw(screen *ebiten.Image) {
+	drawStars(screen, c.stars)
+
+	drawText(screen, "CONTROLS", assets.TitleFont, 48, ScreenWidth/2, 60, text.AlignCenter, color.White)
+
+	y := 130.0
+	for row := range controlsRowCount {
+		clr := color.Color(color.White)
+		if row == c.selected {
+			clr = selectedColor
+		}
+
+		switch row {
+		case controlsRowReset:
+			drawText(screen, "RESET TO DEFAULTS", assets.ScoreFont, 16, ScreenWidth/2, y, text.AlignCenter, clr)
+		case controlsRowBack:
+			drawText(screen, "SAVE AND RETURN", assets.ScoreFont, 16, ScreenWidth/2, y, text.AlignCenter, clr)
+		default:
+			action := Action(row)
+			bindings := "---"
+			if row == c.selected && c.capturing {
+				bindings = "..."
+			} else if labels := c.bindingLabels(action); labels != "" {
+				bindings = labels
+			}
+			drawText(screen, action.Label(), assets.ScoreFont, 16, ScreenWidth/2-40, y, text.AlignEnd, clr)
+			drawText(screen, bindings, assets.ScoreFont, 16, ScreenWidth/2+40, y, text.AlignStart, clr)
+		}
+		y += 26
+	}
+
+	if c.message != "" {
+		drawText(screen, c.message, assets.ScoreFont, 16, ScreenWidth/2, ScreenHeight-40, text.AlignCenter, selectedColor)
+	}
+}
+
+func (c *ControlsScene) bindingLabels(a Action) string {
+	labels := make([]string, 0, len(c.bindings[a]))
+	for _, b := range c.bindings[a] {
+		labels = append(labels, b.Label())
+	}
+	return strings.Join(labels, " / ")
+}
@@ -40,7 +40,7 @@ func (e *Exhaust) Draw(screen *ebiten.Image) {
 	screen.DrawImage(e.sprite, op)
 }
 
-func (e *Exhaust) Update() {
+func (e *Exhaust) Update(maxAcceleration float64) {
 	speed := maxAcceleration / float64(ebiten.TPS())
 	e.position.X += math.Sin(e.rotation) * speed
 	e.position.Y += math.Cos(e.rotation) * -speed
@@ -1,32 +1,84 @@
 package goasteroids
 
 import (
+	"fmt"
 	"go-asteroids/assets"
+	"go-asteroids/sim"
 	"image/color"
-	"os"
+	"log"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/hajimehoshi/ebiten/v2/text/v2"
 )
 
 type GameOverScene struct {
-	game        *GameScene
-	meteors     map[int]*Meteor
+	game *GameScene
+	// input is read for the keys and buttons the prompts ask for.
+	input       *Input
+	meteors     map[int]*sim.Meteor
 	meteorCount int
 	stars       []*Star
+	entry       ScoreEntry
+	newBest     bool
+	// entering is set while the player types their initials for the
+	// leaderboard.
+	entering bool
+	initials [initialsLength]byte
+	cursor   int
+	rank     int
+	chars    []rune
 }
 
-func (o *GameOverScene) Draw(screen *ebiten.Image) {
-	for _, s := range o.stars {
-		s.Draw(screen)
+func NewGameOverScene(game *GameScene, input *Input, entry ScoreEntry) *GameOverScene {
+	o := &GameOverScene{
+		game:        game,
+		input:       input,
+		meteors:     make(map[int]*sim.Meteor),
+		meteorCount: 5,
+		stars:       GenerateStars(game.rng, numberOfStars),
+		entry:       entry,
+		newBest:     !game.cheated() && entry.Score > leaderboard.Best(),
+		entering:    !game.cheated() && leaderboard.Qualifies(entry.Score),
+		rank:        -1,
+	}
+	for i := range o.initials {
+		o.initials[i] = 'A'
 	}
+	return o
+}
+
+func (o *GameOverScene) Draw(screen *ebiten.Image) {
+	drawStars(screen, o.stars)
 
 	for _, m := range o.meteors {
-		m.Draw(screen)
+		drawMeteor(screen, m)
+	}
+
+	if o.newBest {
+		textToDraw := "New High Score!"
+		op := &text.DrawOptions{
+			LayoutOptions: text.LayoutOptions{
+				PrimaryAlign: text.AlignCenter,
+			},
+		}
+		op.ColorScale.ScaleWithColor(color.White)
+		op.GeoM.Translate(ScreenWidth/2, ScreenHeight/2-200)
+		text.Draw(screen, textToDraw, &text.GoTextFace{
+			Source: assets.TitleFont,
+			Size:   48,
+		}, op)
 	}
 
-	textToDraw := "Game Over Press Space to Restart"
+	if o.entering {
+		o.drawInitials(screen)
+		return
+	}
+
+	if o.rank >= 0 {
+		drawText(screen, fmt.Sprintf("%s RANKED #%d", string(o.initials[:]), o.rank+1), assets.ScoreFont, 24, ScreenWidth/2, ScreenHeight/2-60, text.AlignCenter, selectedColor)
+	}
+
+	textToDraw := fmt.Sprintf("Game Over Press %s to Restart", o.input.Prompt(ActionConfirm))
 	op := &text.DrawOptions{
 		LayoutOptions: text.LayoutOptions{
 			PrimaryAlign: text.AlignCenter,
@@ -38,26 +90,36 @@ func (o *GameOverScene) Draw(screen *ebiten.Image) {
 		Source: assets.TitleFont,
 		Size:   48,
 	}, op)
-	
-	if o.game.score > originalHighScore {
-		textToDraw = "New High Score!"
-		op = &text.DrawOptions{
-			LayoutOptions: text.LayoutOptions{
-				PrimaryAlign: text.AlignCenter,
-			},
+
+	drawText(screen, fmt.Sprintf("SEED %d", o.game.world.Seed), assets.ScoreFont, 16, ScreenWidth/2, ScreenHeight/2+160, text.AlignCenter, color.White)
+	if o.canReplay() {
+		drawText(screen, fmt.Sprintf("PRESS %s TO WATCH THE REPLAY", o.input.Prompt(ActionReplay)), assets.ScoreFont, 16, ScreenWidth/2, ScreenHeight/2+190, text.AlignCenter, color.White)
+	}
+}
+
+func (o *GameOverScene) drawInitials(screen *ebiten.Image) {
+	drawText(screen, "ENTER YOUR INITIALS", assets.ScoreFont, 24, ScreenWidth/2, ScreenHeight/2-100, text.AlignCenter, color.White)
+
+	const spacing = 60.0
+	x := ScreenWidth/2 - spacing*float64(initialsLength-1)/2
+	for i, c := range o.initials {
+		clr := color.Color(color.White)
+		if i == o.cursor {
+			clr = selectedColor
 		}
-		op.ColorScale.ScaleWithColor(color.White)
-		op.GeoM.Translate(ScreenWidth/2, ScreenHeight/2-200)
-		text.Draw(screen, textToDraw, &text.GoTextFace{
-			Source: assets.TitleFont,
-			Size:   48,
-		}, op)
+		drawText(screen, string(c), assets.TitleFont, 64, x, ScreenHeight/2-40, text.AlignCenter, clr)
+		x += spacing
 	}
+
+	summary := fmt.Sprintf("%06d  LEVEL %d  %s", o.entry.Score, o.entry.Level, o.entry.Duration())
+	drawText(screen, summary, assets.ScoreFont, 16, ScreenWidth/2, ScreenHeight/2+80, text.AlignCenter, color.White)
 }
 
+func (o *GameOverScene) music() MusicTrack { return MusicGameOver }
+
 func (o *GameOverScene) Update(state *State) error {
 	if len(o.meteors) < 10 {
-		m := NewMeteor(0.25, &GameScene{}, len(o.meteors)-1)
+		m := sim.NewMeteor(o.game.rng, o.game.world.Tuning().Meteors.BaseVelocity, len(o.meteors)-1)
 		o.meteorCount++
 		o.meteors[o.meteorCount] = m
 	}
@@ -66,14 +128,98 @@ func (o *GameOverScene) Update(state *State) error {
 		m.Update()
 	}
 
-	if inpututil.IsKeyJustPressed(ebiten.KeySpace) {
-		o.game.Reset()
+	if o.entering {
+		o.updateInitials(state)
+		return nil
+	}
+
+	if state.Input.IsJustPressed(ActionConfirm) {
+		o.game.Reset(state.Options.gameSeed())
+		state.Camera.Settle()
 		state.SceneManager.GoToScene(o.game)
 	}
 
-	if inpututil.IsKeyJustPressed(ebiten.KeyQ) {
-		os.Exit(0)
+	if state.Input.IsJustPressed(ActionReplay) && o.canReplay() {
+		state.SceneManager.Push(NewReplayScene(o.game.recording, state.Audio))
+	}
+
+	if state.Input.IsJustPressed(ActionQuit) {
+		return ebiten.Termination
 	}
 
 	return nil
 }
+
+// canReplay reports whether the game can be watched again. A game whose rules
+// were reloaded while it ran would not play back the same, so it is neither
+// saved nor offered.
+func (o *GameOverScene) canReplay() bool {
+	return !o.game.rulesChanged
+}
+
+// applyRules passes reloaded rules on to the game, which is reset with them
+// when the player restarts.
+func (o *GameOverScene) applyRules(rules sim.Rules) {
+	o.game.applyRules(rules)
+}
+
+// updateInitials handles arcade style initials entry. Up and down cycle the
+// selected letter, left and right move between letters, and letters typed on
+// a keyboard are taken as they are.
+func (o *GameOverScene) updateInitials(state *State) {
+	o.chars = ebiten.AppendInputChars(o.chars[:0])
+	for _, c := range o.chars {
+		if c >= 'a' && c <= 'z' {
+			c -= 'a' - 'A'
+		}
+		if c >= 'A' && c <= 'Z' {
+			o.initials[o.cursor] = byte(c)
+			o.cursor = min(o.cursor+1, initialsLength-1)
+		}
+	}
+
+	if state.Input.IsJustPressed(ActionUp) {
+		o.initials[o.cursor] = 'A' + (o.initials[o.cursor]-'A'+1)%26
+	}
+
+	if state.Input.IsJustPressed(ActionDown) {
+		o.initials[o.cursor] = 'A' + (o.initials[o.cursor]-'A'+25)%26
+	}
+
+	if state.Input.IsJustPressed(ActionLeft) || state.Input.IsJustPressed(ActionBack) {
+		o.cursor = max(o.cursor-1, 0)
+	}
+
+	if state.Input.IsJustPressed(ActionRight) {
+		o.cursor = min(o.cursor+1, initialsLength-1)
+	}
+
+	if state.Input.IsJustPressed(ActionConfirm) {
+		if o.cursor < initialsLength-1 {
+			o.cursor++
+			return
+		}
+
+		if err := o.commitEntry(); err != nil {
+			log.Println("Error saving leaderboard:", err)
+		}
+	}
+}
+
+// commitEntry puts the score on the leaderboard under the initials entered.
+func (o *GameOverScene) commitEntry() error {
+	o.entering = false
+	o.entry.Initials = string(o.initials[:])
+	o.rank = leaderboard.Insert(o.entry)
+	highScore = leaderboard.Best()
+	return saveLeaderboard(leaderboard)
+}
+
+// OnShutdown keeps a score whose initials are still being entered, with the
+// initials as they stand.
+func (o *GameOverScene) OnShutdown() error {
+	if !o.entering {
+		return nil
+	}
+	return o.commitEntry()
+}
@@ -3,182 +3,312 @@ package goasteroids
 import (
 	"fmt"
 	"go-asteroids/assets"
+	"go-asteroids/sim"
 	"image/color"
 	"log"
 	"math"
-	"math/rand"
+	"math/rand/v2"
 	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/audio"
 	"github.com/hajimehoshi/ebiten/v2/text/v2"
-	"github.com/solarlune/resolv"
 )
 
 const (
-	baseMeteorVelocity   = 0.25
-	meteorSpawnTime      = 100 * time.Millisecond
-	meteorSpeedUpAmount  = 0.1
-	meteorSpeedUpTime    = 1000 * time.Millisecond
-	cleanUpExplosionTime = 200 * time.Millisecond
-	baseBeatWaitTime     = 1600
-	numberOfStars        = 1000
-	alienAttackTime      = 3 * time.Second
-	alienSpawnTime       = 8 * time.Second
-	baseAlienVelocity    = 0.5
+	baseBeatWaitTime = 1600
+	// intenseMeteorCount is the number of meteors at which the drive of the
+	// music is at its loudest.
+	intenseMeteorCount = 12
+	numberOfStars      = 1000
+	// panWidth is how far a sound at the edge of the playfield is panned,
+	// short of coming from one speaker alone.
+	panWidth = 0.8
+	// hearingDistance is how far from the ship a sound has faded to
+	// farthestGain.
+	hearingDistance = 1000
+	farthestGain    = 0.35
+	// explosionTrauma is the trauma an explosion adds to the camera for
+	// every pixel of its radius, and shipTrauma what losing the ship adds
+	// on top. A large meteor breaking up holds the game for hitStopTicks,
+	// and hyperspace zooms in on where the ship arrives by hyperSpaceZoom.
+	explosionTrauma = 0.006
+	shipTrauma      = 0.6
+	hitStopTicks    = 4
+	hyperSpaceZoom  = 1
 )
 
+// GameScene renders a sim.World and plays the sounds for the events it
+// raises. All of the game rules live in the sim package.
 type GameScene struct {
-	player               *Player
-	baseVelocity         float64
-	meteorCount          int
-	meteorsSpawnTimer    *Timer
-	meteors              map[int]*Meteor
-	meteorsForLevel      int
-	velocityTimer        *Timer
-	space                *resolv.Space
-	lasers               map[int]*Laser
-	laserCount           int
-	score                int
-	explosionSmallSprite *ebiten.Image
-	explosionSprite      *ebiten.Image
-	explosionFrames      []*ebiten.Image
-	cleanUpTimer         *Timer
-	playerIsDead         bool
-	audioContext         *audio.Context
-	thrustPlayer         *audio.Player
-	exhaust              *Exhaust
-	laserOnePlayer       *audio.Player
-	laserTwoPlayer       *audio.Player
-	laserThirdPlayer     *audio.Player
-	explosionPlayer      *audio.Player
-	beatOnePlayer        *audio.Player
-	beatTwoPlayer        *audio.Player
-	beatTimer            *Timer
-	beatWaitTime         int
-	playBeatOne          bool
-	stars                []*Star
-	currentLevel         int
-	shield               *Shield
-	shieldsUpPlayer      *audio.Player
-	alienAttackTimer     *Timer
-	alienCount           int
-	alienLaserCount      int
-	alienLaserPlayer     *audio.Player
-	alienLasers          map[int]*AlienLaser
-	alienSoundPLayer     *audio.Player
-	aliens               map[int]*Alien
-	alienSpawnTimer      *Timer
+	world *sim.World
+	// rng drives cosmetic randomness such as the starfield. It is seeded
+	// alongside the world but kept separate so that it never shifts the
+	// sequence gameplay draws from.
+	rng                 *rand.Rand
+	rngSource           *rand.PCG
+	exhaust             *Exhaust
+	shield              *Shield
+	hyperSpaceIndicator *HyperSpaceIndicator
+	particles           *ParticleSystem
+	trail               stream
+	audio               *AudioManager
+	stars               []*Star
+	// The heartbeat keeps time with the music, sounding every beatWaitTime
+	// milliseconds rounded to a whole number of beats of it. lastBeat is the
+	// beat of the music last seen and beatsWaited those since the heartbeat.
+	beatWaitTime int
+	playBeatOne  bool
+	lastBeat     int
+	beatsWaited  int
+	// recording collects the input of every tick stepped in a live game.
+	recording *sim.Replay
+	// playback, when set, drives the world from a replay instead of the
+	// player's input.
+	playback     *sim.Replay
+	playbackTick int
+	// track is the path of the ship in a live game, kept as the new ghost
+	// if the game beats the best score.
+	track     *Ghost
+	ghost     *Ghost
+	showGhost bool
+	// pendingRules are reloaded rules waiting to be applied on the next
+	// tick. Once they have been, rulesChanged stops the game being kept as a
+	// replay or ghost, since neither could reproduce it.
+	pendingRules *sim.Rules
+	rulesChanged bool
 }
 
-func NewGameScene() *GameScene {
+func NewGameScene(seed uint64, rules sim.Rules, audio *AudioManager) *GameScene {
 	g := &GameScene{
-		meteorsSpawnTimer:    NewTimer(meteorSpawnTime),
-		baseVelocity:         baseMeteorVelocity,
-		velocityTimer:        NewTimer(meteorSpeedUpTime),
-		meteors:              make(map[int]*Meteor),
-		meteorsForLevel:      2,
-		meteorCount:          0,
-		space:                resolv.NewSpace(ScreenWidth, ScreenHeight, 16, 16),
-		lasers:               make(map[int]*Laser),
-		laserCount:           0,
-		explosionSprite:      assets.ExplosionSprite,
-		explosionSmallSprite: assets.ExplosionSmallSprite,
-		cleanUpTimer:         NewTimer(cleanUpExplosionTime),
-		beatTimer:            NewTimer(2 * time.Second),
-		beatWaitTime:         baseBeatWaitTime,
-		currentLevel:         1,
-		aliens:               make(map[int]*Alien),
-		alienCount:           0,
-		alienLasers:          make(map[int]*AlienLaser),
-		alienLaserCount:      0,
-		alienSpawnTimer:      NewTimer(alienSpawnTime),
-		alienAttackTimer:     NewTimer(alienAttackTime),
-	}
-	g.player = NewPlayer(g)
-	g.space.Add(g.player.playerObj)
-	g.stars = GenerateStars(numberOfStars)
-
-	g.explosionFrames = assets.Explosion
-	g.audioContext = audio.NewContext(48000)
-	g.thrustPlayer, _ = g.audioContext.NewPlayer(assets.ThrustSound)
-	g.laserOnePlayer, _ = g.audioContext.NewPlayer(assets.LaserOneSound)
-	g.laserTwoPlayer, _ = g.audioContext.NewPlayer(assets.LaserTwoSound)
-	g.laserThirdPlayer, _ = g.audioContext.NewPlayer(assets.LaserThirdSound)
-	g.explosionPlayer, _ = g.audioContext.NewPlayer(assets.ExplosionSound)
-	g.beatOnePlayer, _ = g.audioContext.NewPlayer(assets.BeatOneSound)
-	g.beatTwoPlayer, _ = g.audioContext.NewPlayer(assets.BeatTwoSound)
-	g.shieldsUpPlayer, _ = g.audioContext.NewPlayer(assets.ShieldSound)
-	g.alienSoundPLayer, _ = g.audioContext.NewPlayer(assets.AlienSound)
-	g.alienSoundPLayer.SetVolume(0.5)
-	g.alienLaserPlayer, _ = g.audioContext.NewPlayer(assets.AlienLaserSound)
+		world:               sim.NewWorld(seed, rules),
+		rngSource:           rand.NewPCG(seed, ^seed),
+		hyperSpaceIndicator: NewHyperSpaceIndicator(Vector{}),
+		particles:           NewParticleSystem(),
+		trail:               stream{emitter: trailEmitter},
+		beatWaitTime:        baseBeatWaitTime,
+		recording:           &sim.Replay{Seed: seed, Rules: rules},
+		audio:               audio,
+		track:               &Ghost{},
+		ghost:               loadBestGhost(),
+	}
+	g.rng = rand.New(g.rngSource)
+	g.stars = GenerateStars(g.rng, numberOfStars)
+
+	return g
+}
+
+// NewPlaybackScene returns a GameScene that plays r back instead of reading
+// the player's input.
+func NewPlaybackScene(r *sim.Replay, audio *AudioManager) *GameScene {
+	g := NewGameScene(r.Seed, r.Rules, audio)
+	g.recording = nil
+	g.playback = r
+	g.track = nil
+	g.ghost = nil
 	return g
 }
 
 func (g *GameScene) Update(state *State) error {
-	g.player.Update()
+	if g.playback == nil {
+		if state.Input.IsJustPressed(ActionQuit) {
+			g.quitToTitle(state)
+			return nil
+		}
+
+		if state.Input.IsJustPressed(ActionPause) || !ebiten.IsFocused() {
+			state.SceneManager.Push(NewPauseScene(g), Instant{})
+			return nil
+		}
+	}
+
+	if state.Camera.Held() {
+		return nil
+	}
+
+	in, ok := g.nextInput(state)
+	if !ok {
+		return nil
+	}
+
+	if g.pendingRules != nil {
+		g.world.SetRules(*g.pendingRules)
+		g.pendingRules = nil
+		g.rulesChanged = true
+	}
+
+	events := g.world.Step(in)
+	if g.track != nil {
+		g.track.Frames = append(g.track.Frames, newGhostFrame(g.world.Player))
+	}
+	g.showGhost = state.Settings.Ghost
+
+	g.particles.Update()
 
 	g.updateExhaust()
 
 	g.updateShield()
 
-	g.isPlayerDying()
+	g.updateThrustSound()
 
-	g.isPlayerDead(state)
+	g.updateAlienSound()
 
-	g.spawnMeteors()
+	for _, e := range events {
+		g.handleEvent(state, e)
+	}
 
-	g.spawnAliens()
+	g.updateMusic()
 
-	for _, a := range g.aliens {
-		a.Update()
-	}
+	g.beatSound()
 
-	g.letAliensAttack()
+	return nil
+}
 
-	for _, al := range g.alienLasers {
-		al.Update()
+// quitToTitle puts the game aside in the save slot, to be continued from
+// the title.
+func (g *GameScene) quitToTitle(state *State) {
+	if err := g.suspend(); err != nil {
+		log.Println("Error saving game:", err)
 	}
+	state.Camera.Settle()
+	state.SceneManager.GoToScene(NewTitleScene(state.Input))
+}
 
-	for _, m := range g.meteors {
-		m.Update()
+// nextInput returns the input for the coming tick. A live game records it,
+// quantized the same way the replay stores it so that playing the replay back
+// steps the world identically. It reports false once a playback has run out of
+// frames.
+func (g *GameScene) nextInput(state *State) (sim.Input, bool) {
+	if g.playback != nil {
+		if g.PlaybackDone() {
+			return sim.Input{}, false
+		}
+		f := g.playback.Frames[g.playbackTick]
+		g.playbackTick++
+		return f.Input(), true
 	}
 
-	for _, l := range g.lasers {
-		l.Update()
+	if g.world.Phase != sim.PhasePlaying {
+		return sim.Input{}, false
 	}
 
-	g.speedUpMeteors()
+	f := sim.NewFrame(state.Input.PlayerInput())
+	g.recording.Frames = append(g.recording.Frames, f)
+	return f.Input(), true
+}
+
+// PlaybackDone reports whether a playback has stepped every recorded frame.
+func (g *GameScene) PlaybackDone() bool {
+	return g.playback != nil && (g.playbackTick >= len(g.playback.Frames) || g.world.Phase == sim.PhaseGameOver)
+}
 
-	g.isPlayerCollidingWithMeteor()
+func (g *GameScene) handleEvent(state *State, e sim.Event) {
+	switch e.Kind {
+	case sim.EventLaserFired:
+		g.audio.PlayAt(SoundLaser, g.placement(e.Position))
+	case sim.EventAlienLaserFired:
+		g.audio.PlayAt(SoundAlienLaser, g.placement(e.Position))
+	case sim.EventExplosion:
+		g.audio.PlayAt(SoundExplosion, g.placement(e.Position))
+		explode(g.particles, g.rng, e)
+		state.Camera.AddTrauma(e.Radius * explosionTrauma)
+		if e.Large {
+			state.Camera.HitStop(hitStopTicks)
+		}
+	case sim.EventShipDestroyed:
+		state.Camera.AddTrauma(shipTrauma)
+	case sim.EventShieldHit:
+		sparkShield(g.particles, g.rng, e, g.world.Player.Center())
+	case sim.EventHyperSpace:
+		warp(g.particles, g.rng, e)
+		state.Camera.Zoom(e.Position, hyperSpaceZoom)
+	case sim.EventShieldUp:
+		g.audio.PlayAt(SoundShieldUp, g.placement(g.world.Player.Center()))
+	case sim.EventLifeLost:
+		g.exhaust = nil
+		g.shield = nil
+	case sim.EventLevelComplete:
+		state.Camera.Settle()
+		g.beatWaitTime = baseBeatWaitTime
+		if g.playback != nil {
+			// A replay carries straight on, as the recording did after the
+			// level start screen.
+			g.world.StartNextLevel()
+			return
+		}
 
-	g.isMeteorHitByPlayerLaser()
+		state.SceneManager.GoToScene(&LevelStartScene{
+			game:           g,
+			nextLevelTimer: NewTimer(time.Second * 2),
+			stars:          GenerateStars(g.rng, numberOfStars),
+		}, Wipe{Length: defaultTransitionTicks, Direction: WipeLeft})
+	case sim.EventGameOver:
+		state.Camera.Settle()
+		g.pauseSounds()
+		if g.playback != nil {
+			return
+		}
 
-	g.isPlayerCollidingWithAlien()
+		state.Session.recordGame(g.world.Score)
 
-	g.isPlayerHitByAlienLaser()
+		if g.rulesChanged {
+			log.Println("Rules changed during the game, not saving a replay or ghost")
+		} else {
+			if _, err := saveReplay(g.recording); err != nil {
+				log.Println("Error saving replay:", err)
+			}
 
-	g.isAlienHitByPlayerLaser()
+			if !g.cheated() {
+				g.track.Score = g.world.Score
+				offerGhost(g.track)
+			}
+		}
 
-	g.cleanUp()
+		state.SceneManager.GoToScene(NewGameOverScene(g, state.Input, ScoreEntry{
+			Score:   g.world.Score,
+			Level:   g.world.CurrentLevel,
+			Date:    time.Now(),
+			Seconds: g.world.Tick / sim.TicksPerSecond,
+		}))
+	}
+}
 
-	g.beatSound()
+// cheated reports whether the rules let the game start past the first level
+// or keep the ship from being destroyed. Such a game is kept off the
+// leaderboard and never becomes the ghost.
+func (g *GameScene) cheated() bool {
+	rules := g.world.Rules()
+	return rules.GodMode || rules.StartLevel > 1
+}
 
-	g.isLevelComplete(state)
+// OnSuspend and OnExit silence the game when it is covered or left.
+func (g *GameScene) OnSuspend() { g.pauseSounds() }
 
-	g.removeOffScreenAliens()
+func (g *GameScene) OnExit() { g.pauseSounds() }
 
-	g.removeOffScreenLasers()
+// OnShutdown puts a live game in progress aside to be continued, as quitting
+// to the title does.
+func (g *GameScene) OnShutdown() error {
+	if g.playback != nil || g.world.Phase != sim.PhasePlaying {
+		return nil
+	}
+	return g.suspend()
+}
 
-	return nil
+// pauseSounds stops the looping sounds, which would otherwise keep playing
+// while the scene is not being updated.
+func (g *GameScene) pauseSounds() {
+	g.audio.Stop(SoundThrust)
+	g.audio.Stop(SoundAlien)
 }
 
 func (g *GameScene) Draw(screen *ebiten.Image) {
-	for _, s := range g.stars {
-		s.Draw(screen)
+	drawStars(screen, g.stars)
+
+	if g.showGhost && g.ghost != nil && g.track != nil {
+		drawGhost(screen, g.ghost, len(g.track.Frames)-1)
 	}
 
-	g.player.Draw(screen)
+	drawPlayer(screen, g.world.Player)
 
 	if g.exhaust != nil {
 		g.exhaust.Draw(screen)
@@ -188,53 +318,61 @@ func (g *GameScene) Draw(screen *ebiten.Image) {
 		g.shield.Draw(screen)
 	}
 
-	for _, m := range g.meteors {
-		m.Draw(screen)
+	for _, m := range g.world.Meteors {
+		drawMeteor(screen, m)
 	}
 
-	for _, l := range g.lasers {
-		l.Draw(screen)
+	for _, l := range g.world.Lasers {
+		drawLaser(screen, l)
 	}
 
-	if len(g.player.lifeIndicators) > 0 {
-		for _, li := range g.player.lifeIndicators {
-			li.Draw(screen)
-		}
+	for _, a := range g.world.Aliens {
+		drawAlien(screen, a)
 	}
 
-	if len(g.player.shieldIndicators) > 0 {
-		for _, si := range g.player.shieldIndicators {
-			si.Draw(screen)
-		}
+	for _, al := range g.world.AlienLasers {
+		drawAlienLaser(screen, al)
 	}
 
-	if g.player.hyperSpaceTimer == nil || g.player.hyperSpaceTimer.IsReady() {
-		g.player.hyperSpaceIndicator.Draw(screen)
+	g.particles.Draw(screen)
+}
+
+// DrawHUD draws the lives, shields and hyperspace indicators in the top left
+// corner of the view and the scores along its top and bottom edges.
+func (g *GameScene) DrawHUD(screen *ebiten.Image, view viewport) {
+	x, y := view.anchor(anchorTopLeft, 20, 20)
+	for range g.world.Player.LivesRemaining {
+		NewLifeIndicator(Vector{X: x, Y: y}, 0).Draw(screen)
+		x += 50.0
 	}
 
-	for _, a := range g.aliens {
-		a.Draw(screen)
+	x, y = view.anchor(anchorTopLeft, 45, 60)
+	for range g.world.Player.ShieldRemaining {
+		NewShieldIndicator(Vector{X: x, Y: y}).Draw(screen)
+		x += 50.0
 	}
 
-	for _, al := range g.alienLasers {
-		al.Draw(screen)
+	if g.world.Player.HyperSpaceReady() {
+		x, y = view.anchor(anchorTopLeft, 37, 95)
+		g.hyperSpaceIndicator.position = Vector{X: x, Y: y}
+		g.hyperSpaceIndicator.Draw(screen)
 	}
 
-	textToDraw := fmt.Sprintf("%06d", g.score)
+	textToDraw := fmt.Sprintf("%06d", g.world.Score)
 	op := &text.DrawOptions{
 		LayoutOptions: text.LayoutOptions{
 			PrimaryAlign: text.AlignCenter,
 		},
 	}
 	op.ColorScale.ScaleWithColor(color.White)
-	op.GeoM.Translate(ScreenWidth/2, 40)
+	op.GeoM.Translate(view.anchor(anchorTop, 0, 40))
 	text.Draw(screen, textToDraw, &text.GoTextFace{
 		Source: assets.ScoreFont,
 		Size:   24,
 	}, op)
 
-	if g.score > highScore {
-		highScore = g.score
+	if g.playback == nil && !g.cheated() && g.world.Score > highScore {
+		highScore = g.world.Score
 	}
 
 	textToDraw = fmt.Sprintf("HIGH SCORE %06d", highScore)
@@ -244,406 +382,180 @@ func (g *GameScene) Draw(screen *ebiten.Image) {
 		},
 	}
 	op.ColorScale.ScaleWithColor(color.White)
-	op.GeoM.Translate(ScreenWidth/2, 75)
+	op.GeoM.Translate(view.anchor(anchorTop, 0, 75))
 	text.Draw(screen, textToDraw, &text.GoTextFace{
 		Source: assets.ScoreFont,
 		Size:   16,
 	}, op)
 
-	textToDraw = fmt.Sprintf("LEVEL %d", g.currentLevel)
+	textToDraw = fmt.Sprintf("LEVEL %d", g.world.CurrentLevel)
 	op = &text.DrawOptions{
 		LayoutOptions: text.LayoutOptions{
 			PrimaryAlign: text.AlignCenter,
 		},
 	}
 	op.ColorScale.ScaleWithColor(color.White)
-	op.GeoM.Translate(ScreenWidth/2, ScreenHeight-40)
+	op.GeoM.Translate(view.anchor(anchorBottom, 0, 40))
 	text.Draw(screen, textToDraw, &text.GoTextFace{
 		Source: assets.LevelFont,
 		Size:   16,
 	}, op)
-}
-
-func (g *GameScene) Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeight int) {
-	return outsideWidth, outsideHeight
-}
-
-func (g *GameScene) isPlayerCollidingWithAlien() {
-	for _, a := range g.aliens {
-		if a.alienObj.IsIntersecting(g.player.playerObj) {
-			if !a.game.player.isShielded {
-				if !a.game.explosionPlayer.IsPlaying() {
-					a.game.explosionPlayer.Rewind()
-					a.game.explosionPlayer.Play()
-				}
-				a.game.player.isDying = true
-			}
-		}
-	}
-}
 
-func (g *GameScene) isPlayerHitByAlienLaser() {
-	for _, l := range g.alienLasers {
-		if l.laserObj.IsIntersecting(g.player.playerObj) {
-			if !g.player.isShielded {
-				if !g.explosionPlayer.IsPlaying() {
-					g.explosionPlayer.Rewind()
-					g.explosionPlayer.Play()
-				}
-				g.player.isDying = true
-			}
-		}
-	}
+	x, y = view.anchor(anchorBottomRight, 20, 40)
+	drawText(screen, fmt.Sprintf("SEED %d", g.world.Seed), assets.ScoreFont, 12, x, y, text.AlignEnd, color.Gray{Y: 0x80})
 }
 
-func (g *GameScene) isAlienHitByPlayerLaser() {
-	for _, a := range g.aliens {
-		for _, l := range g.lasers {
-			if a.alienObj.IsIntersecting(l.laserObj) {
-				laserData := l.laserObj.Data().(*ObjectData)
-				delete(g.alienLasers, laserData.index)
-				g.space.Remove(l.laserObj)
-				a.sprite = g.explosionSprite
-				g.score += g.score + 50
-				if !g.explosionPlayer.IsPlaying() {
-					g.explosionPlayer.Rewind()
-					g.explosionPlayer.Play()
-				}
-			}
-		}
-	}
-}
-
-func (g *GameScene) letAliensAttack() {
-	if len(g.aliens) > 0 {
-		if !g.alienSoundPLayer.IsPlaying() {
-			g.alienSoundPLayer.Rewind()
-			g.alienSoundPLayer.Play()
-		}
-
-		g.alienAttackTimer.Update()
-
-		if g.alienAttackTimer.IsReady() {
-			g.alienAttackTimer.Reset()
-			for _, a := range g.aliens {
-				halfW, halfH := HalfOfTheImage(a.sprite)
-
-				var degreesRadian float64
-				if !a.isIntelligent {
-					degreesRadian = rand.Float64() * (math.Pi * 2)
-				} else {
-					degreesRadian = math.Atan2(g.player.position.Y-a.position.Y, g.player.position.X-a.position.X)
-					degreesRadian = degreesRadian - math.Pi*-0.5
-				}
-
-				r := degreesRadian
-
-				offsetX := float64(a.sprite.Bounds().Dx() - int(halfW))
-				offsetY := float64(a.sprite.Bounds().Dy() - int(halfH))
-
-				spawnPos := Vector{
-					X: a.position.X + halfW + math.Sin(r) - offsetX,
-					Y: a.position.Y + halfH + math.Cos(r) - offsetY,
-				}
-
-				laser := NewAlienLaser(spawnPos, r)
-				g.alienLaserCount++
-				g.alienLasers[g.alienLaserCount] = laser
-				if !g.alienLaserPlayer.IsPlaying() {
-					_ = g.alienLaserPlayer.Rewind()
-					g.alienLaserPlayer.Play()
-				}
-			}
-		}
-
-	}
-}
-
-func (g *GameScene) removeOffScreenLasers() {
-	for i, l := range g.lasers {
-		if l.position.X > ScreenWidth+200 || l.position.Y > ScreenHeight+200 || l.position.X < -200 || l.position.Y < -200 {
-			g.space.Remove(l.laserObj)
-			delete(g.lasers, i)
-		}
+func (g *GameScene) updateShield() {
+	p := g.world.Player
+	if !p.Shielded {
+		g.shield = nil
+		return
 	}
 
-	for i, l := range g.alienLasers {
-		if l.position.X > ScreenWidth+200 || l.position.Y > ScreenHeight+200 || l.position.X < -200 || l.position.Y < -200 {
-			g.space.Remove(l.laserObj)
-			delete(g.alienLasers, i)
-		}
+	if g.shield == nil {
+		g.shield = NewShield()
 	}
+	g.shield.Update(p)
 }
 
-func (g *GameScene) spawnAliens() {
-	g.alienSpawnTimer.Update()
-	if len(g.aliens) <= 3 {
-		if g.alienSpawnTimer.IsReady() {
-			g.alienSpawnTimer.Reset()
-			rnd := rand.Intn(100-1) + 1
-			if rnd > 25 {
-				a := NewAlien(baseAlienVelocity, g)
-				g.space.Add(a.alienObj)
-				g.alienCount++
-				g.aliens[g.alienCount] = a
-			}
-		}
+func (g *GameScene) updateThrustSound() {
+	p := g.world.Player
+	if p.Thrusting || p.Reversing {
+		g.audio.PlayAt(SoundThrust, g.placement(g.world.Player.Center()))
+	} else {
+		g.audio.Stop(SoundThrust)
 	}
 }
 
-func (g *GameScene) removeOffScreenAliens() {
-	for i, a := range g.aliens {
-		if a.position.X > ScreenWidth+200 || a.position.Y > ScreenHeight+200 || a.position.X < -200 || a.position.Y < -200 {
-			g.space.Remove(a.alienObj)
-			delete(g.aliens, i)
+// updateAlienSound keeps the alien hum playing from the nearest alien for as
+// long as there are any.
+func (g *GameScene) updateAlienSound() {
+	ship := g.world.Player.Center()
+	var nearest sim.Vector
+	nearestDistance := math.Inf(1)
+	for _, a := range g.world.Aliens {
+		if d := math.Hypot(a.Position.X-ship.X, a.Position.Y-ship.Y); d < nearestDistance {
+			nearest, nearestDistance = a.Position, d
 		}
 	}
-}
 
-func (g *GameScene) updateShield() {
-	if g.shield != nil {
-		g.shield.Update()
+	if math.IsInf(nearestDistance, 1) {
+		g.audio.Stop(SoundAlien)
+		return
 	}
+	g.audio.PlayAt(SoundAlien, g.placement(nearest))
 }
 
-func (g *GameScene) isLevelComplete(state *State) {
-	if g.meteorCount >= g.meteorsForLevel && len(g.meteors) == 0 {
-		g.baseVelocity = baseMeteorVelocity
-		g.currentLevel++
-
-		if g.currentLevel%5 == 0 {
-			if g.player.livesRemaining < 6 {
-				g.player.livesRemaining++
-				x := float64(20 + (len(g.player.lifeIndicators) * 50))
-				y := 20.0
-				g.player.lifeIndicators = append(g.player.lifeIndicators, NewLifeIndicator(Vector{X: x, Y: y}, 0))
-			}
-		}
-
-		g.beatWaitTime = baseBeatWaitTime
-		state.SceneManager.GoToScene(&LevelStartScene{
-			game:           g,
-			nextLevelTimer: NewTimer(time.Second * 2),
-			stars:          GenerateStars(numberOfStars),
-		})
+// placement returns where a sound made at pos in the playfield is heard from,
+// panned by how far across the playfield it is and quieter the further it is
+// from the ship.
+func (g *GameScene) placement(pos sim.Vector) Placement {
+	ship := g.world.Player.Center()
+	distance := min(math.Hypot(pos.X-ship.X, pos.Y-ship.Y)/hearingDistance, 1)
+	return Placement{
+		Pan:  (pos.X/sim.PlayfieldWidth*2 - 1) * panWidth,
+		Gain: 1 - distance*(1-farthestGain),
 	}
 }
 
+// beatSound sounds the heartbeat on a beat of the music, every time the
+// beats since the last one make up the wait. The wait shortens with each
+// heartbeat until it is down to a single beat.
 func (g *GameScene) beatSound() {
-	g.beatTimer.Update()
-	if g.beatTimer.IsReady() {
-		if g.playBeatOne {
-			g.beatOnePlayer.Rewind()
-			g.beatOnePlayer.Play()
-			g.beatTimer.Reset()
-		} else {
-			g.beatTwoPlayer.Rewind()
-			g.beatTwoPlayer.Play()
-			g.beatTimer.Reset()
-		}
-		g.playBeatOne = !g.playBeatOne
+	beat, length, ok := g.audio.MusicBeat()
+	if !ok || beat == g.lastBeat {
+		return
+	}
+	g.lastBeat = beat
+	g.beatsWaited++
 
-		if g.beatWaitTime > 400 {
-			g.beatWaitTime = g.beatWaitTime - 25
-			g.beatTimer = NewTimer(time.Millisecond * time.Duration(g.beatWaitTime))
-		}
+	wait := time.Duration(g.beatWaitTime) * time.Millisecond
+	if g.beatsWaited < max(1, int(math.Round(float64(wait)/float64(length)))) {
+		return
 	}
-}
+	g.beatsWaited = 0
 
-func (g *GameScene) updateExhaust() {
-	if g.exhaust != nil {
-		g.exhaust.Update()
+	if g.playBeatOne {
+		g.audio.Play(SoundBeatOne)
+	} else {
+		g.audio.Play(SoundBeatTwo)
 	}
-}
+	g.playBeatOne = !g.playBeatOne
 
-func (g *GameScene) isPlayerDying() {
-	if g.player.isDying {
-		g.player.dyingTimer.Update()
-		if g.player.dyingTimer.IsReady() {
-			g.player.dyingTimer.Reset()
-			g.player.dyingCounter++
-			if g.player.dyingCounter == 12 {
-				g.player.isDying = false
-				g.player.isDead = true
-			} else if g.player.dyingCounter < 12 {
-				g.player.sprite = g.explosionFrames[g.player.dyingCounter]
-			} else {
-				//Do nothing
-			}
-		}
+	if g.beatWaitTime > 400 {
+		g.beatWaitTime = g.beatWaitTime - 25
 	}
 }
 
-func (g *GameScene) isPlayerDead(state *State) {
-	if g.playerIsDead {
-		g.player.livesRemaining--
-		if g.player.livesRemaining == 0 {
-
-			if g.score >= highScore {
-				highScore = g.score
-				if err := updateHighScore(highScore); err != nil {
-					log.Println("Error updating high score:", err)
-				}
-			}
+func (g *GameScene) music() MusicTrack { return MusicGameplay }
 
-			state.SceneManager.GoToScene(&GameOverScene{
-				game:        g,
-				meteors:     make(map[int]*Meteor),
-				meteorCount: 5,
-				stars:       GenerateStars(numberOfStars),
-			})
-		} else {
-			score := g.score
-			livesRemaining := g.player.livesRemaining
-			lifeSlice := g.player.lifeIndicators[:len(g.player.lifeIndicators)-1]
-			stars := g.stars
-			shieldsRemaining := g.player.shieldRemaining
-			shieldIndicatorSlice := g.player.shieldIndicators
-			g.Reset()
-			g.score = score
-			g.player.livesRemaining = livesRemaining
-			g.player.lifeIndicators = lifeSlice
-			g.stars = stars
-			g.player.shieldRemaining = shieldsRemaining
-			g.player.shieldIndicators = shieldIndicatorSlice
-		}
+// updateMusic brings in the drive of the music as the meteors multiply and
+// its alien layer while there are aliens.
+func (g *GameScene) updateMusic() {
+	g.audio.SetStemLevel(StemDrive, float64(len(g.world.Meteors))/intenseMeteorCount)
+	alien := 0.0
+	if len(g.world.Aliens) > 0 {
+		alien = 1
 	}
+	g.audio.SetStemLevel(StemAlien, alien)
 }
 
-func (g *GameScene) isMeteorHitByPlayerLaser() {
-	for _, m := range g.meteors {
-		for _, l := range g.lasers {
-			if m.meteorObj.IsIntersecting(l.laserObj) {
-				if m.meteorObj.Tags().Has(TagSmall) {
-					m.sprite = g.explosionSmallSprite
-					g.score++
-
-					if !g.explosionPlayer.IsPlaying() {
-						g.explosionPlayer.Rewind()
-						g.explosionPlayer.Play()
-					}
-				} else {
-					oldPos := m.position
-
-					m.sprite = g.explosionSprite
-
-					g.score++
-
-					if !g.explosionPlayer.IsPlaying() {
-						g.explosionPlayer.Rewind()
-						g.explosionPlayer.Play()
-					}
-
-					numToSpawn := rand.Intn(numberOfSmallMeteorsFromLargeMeteor)
-					for range numToSpawn {
-						meteor := NewSmallMeteor(baseMeteorVelocity, g, len(m.game.meteors)-1)
-						meteor.position = Vector{oldPos.X + float64(rand.Intn(100-50)+50), oldPos.Y + float64(rand.Intn(100-50)+50)}
-						meteor.meteorObj.SetPosition(meteor.position.X, meteor.position.Y)
-						g.space.Add(meteor.meteorObj)
-
-						g.meteorCount++
-						g.meteors[m.game.meteorCount] = meteor
-					}
-				}
-			}
+// updateExhaust places the engine flame behind the ship while it is thrusting
+// or reversing and lets it trail off for the rest of the tick. The engine
+// leaves a trail of particles as it goes.
+func (g *GameScene) updateExhaust() {
+	p := g.world.Player
+	halfW, halfH := sim.PlayerSize.Half()
+
+	var spawnPos Vector
+	switch {
+	case p.Thrusting:
+		spawnPos = Vector{
+			X: p.Position.X + halfW + math.Sin(p.Rotation)*exhaustSpawnOffset,
+			Y: p.Position.Y + halfH + math.Cos(p.Rotation)*-exhaustSpawnOffset,
 		}
-	}
-}
-
-func (g *GameScene) spawnMeteors() {
-	g.meteorsSpawnTimer.Update()
-	if g.meteorsSpawnTimer.IsReady() {
-		g.meteorsSpawnTimer.Reset()
-		if len(g.meteors) < g.meteorsForLevel && g.meteorCount < g.meteorsForLevel {
-			m := NewMeteor(g.baseVelocity, g, len(g.meteors)-1)
-			g.space.Add(m.meteorObj)
-			g.meteorCount++
-			g.meteors[g.meteorCount] = m
+		g.exhaust = NewExhaust(spawnPos, p.Rotation+180.0*math.Pi/180.0)
+	case p.Reversing:
+		spawnPos = Vector{
+			X: p.Position.X + halfW + math.Sin(p.Rotation)*-exhaustSpawnOffset,
+			Y: p.Position.Y + halfH + math.Cos(p.Rotation)*exhaustSpawnOffset,
 		}
+		g.exhaust = NewExhaust(spawnPos, p.Rotation+180.0*math.Pi/180.0)
+	default:
+		g.exhaust = nil
 	}
-}
-
-func (g *GameScene) speedUpMeteors() {
-	g.velocityTimer.Update()
-	if g.velocityTimer.IsReady() {
-		g.velocityTimer.Reset()
-		g.baseVelocity += meteorSpeedUpAmount
-	}
-}
 
-func (g *GameScene) isPlayerCollidingWithMeteor() {
-	for _, m := range g.meteors {
-		if m.meteorObj.IsIntersecting(g.player.playerObj) {
-			if !g.player.isShielded {
-				m.game.player.isDying = true
-
-				if !g.explosionPlayer.IsPlaying() {
-					g.explosionPlayer.Rewind()
-					g.explosionPlayer.Play()
-				}
-				break
-			} else {
-				g.bounceMeteor(m)
-			}
-		}
+	if g.exhaust != nil {
+		g.exhaust.Update(g.world.Tuning().Player.MaxAcceleration)
+		g.trail.emit(g.particles, g.rng, spawnPos, exhaustDirection(p.Rotation, p.Reversing), Vector{})
 	}
 }
 
-func (g *GameScene) bounceMeteor(m *Meteor) {
-	direction := Vector{
-		X: (ScreenWidth/2 - m.position.X) * -1,
-		Y: (ScreenHeight/2 - m.position.Y) * -1,
-	}
-	normalizeDirection := direction.Normalize()
-	velocity := g.baseVelocity
-
-	movement := Vector{
-		X: normalizeDirection.X * velocity,
-		Y: normalizeDirection.Y * velocity,
+// Reset starts a new game from seed on the same scene.
+func (g *GameScene) Reset(seed uint64) {
+	if g.pendingRules != nil {
+		g.world.SetRules(*g.pendingRules)
+		g.pendingRules = nil
 	}
-
-	m.movement = movement
+	g.world.Reset(seed)
+	g.rngSource = rand.NewPCG(seed, ^seed)
+	g.rng = rand.New(g.rngSource)
+	g.exhaust = nil
+	g.shield = nil
+	g.particles.Clear()
+	g.beatWaitTime = baseBeatWaitTime
+	g.beatsWaited = 0
+	g.stars = GenerateStars(g.rng, numberOfStars)
+	g.recording = &sim.Replay{Seed: seed, Rules: g.world.Rules()}
+	g.track = &Ghost{}
+	g.ghost = loadBestGhost()
+	g.rulesChanged = false
 }
 
-func (g *GameScene) cleanUp() {
-	g.cleanUpTimer.Update()
-	if g.cleanUpTimer.IsReady() {
-		for i, m := range g.meteors {
-			if m.sprite == g.explosionSprite || m.sprite == g.explosionSmallSprite {
-				delete(g.meteors, i)
-				g.space.Remove(m.meteorObj)
-			}
-		}
-
-		for i, a := range g.aliens {
-			if a.sprite == g.explosionSprite {
-				delete(g.aliens, i)
-				g.space.Remove(a.alienObj)
-			}
-		}
-		g.cleanUpTimer.Reset()
+// applyRules has a live game pick up reloaded rules on its next tick. A replay
+// keeps the rules it was recorded with.
+func (g *GameScene) applyRules(rules sim.Rules) {
+	if g.playback == nil {
+		g.pendingRules = &rules
 	}
 }
-
-func (g *GameScene) Reset() {
-	g.player = NewPlayer(g)
-	g.meteors = make(map[int]*Meteor)
-	g.meteorCount = 0
-	g.meteorsSpawnTimer.Reset()
-	g.lasers = make(map[int]*Laser)
-	g.laserCount = 0
-	g.score = 0
-	g.baseVelocity = baseMeteorVelocity
-	g.velocityTimer.Reset()
-	g.playerIsDead = false
-	g.exhaust = nil
-	g.space.RemoveAll()
-	g.space.Add(g.player.playerObj)
-	g.stars = GenerateStars(numberOfStars)
-	g.player.shieldRemaining = numberOfShields
-	g.player.isShielded = false
-	g.aliens = make(map[int]*Alien)
-	g.alienLasers = make(map[int]*AlienLaser)
-	g.alienCount = 0
-	g.alienLaserCount = 0
-}
@@ -1,39 +1,196 @@
 package goasteroids
 
-import "github.com/hajimehoshi/ebiten/v2"
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
 
 type Game struct {
 	sceneManager *SceneManager
 	input        Input
+	options      Options
+	settings     Settings
+	audio        *AudioManager
+	rulesWatcher *rulesWatcher
+	toast        Toast
+	// view is the logical image the scenes are drawn on before it is
+	// scaled to the window. backdrop is the starfield shown around the
+	// playfield when the view is extended.
+	view          *ebiten.Image
+	backdrop      []*Star
+	session       Session
+	shutdownHooks []shutdownHook
+	// applied is the settings as they were last applied, so that only
+	// changes are passed on to ebiten.
+	applied Settings
 }
 
-type Input struct {
-}
+// NewGame loads the settings and applies those ebiten holds, such as
+// fullscreen, so that the window opens the way it was left. Overrides from
+// the command line only change the settings of the session: the options
+// menu saves the settings the player changes, not them.
+func NewGame(options Options) *Game {
+	dataDir = options.DataDir
+	g := &Game{options: options}
 
-func (i *Input) Update() {
+	var err error
+	g.settings, err = loadSettings()
+	if err != nil {
+		log.Println("Error loading settings:", err)
+	}
+	if options.Fullscreen != nil {
+		g.settings.Fullscreen = *options.Fullscreen
+	}
+	if options.Vsync != nil {
+		g.settings.Vsync = *options.Vsync
+	}
 
+	setFullscreen(g.settings.Fullscreen)
+	ebiten.SetVsyncEnabled(g.settings.Vsync)
+	g.applied = g.settings
+	return g
 }
 
 func (g *Game) Update() error {
 	if (g.sceneManager) == nil {
-		g.sceneManager = &SceneManager{}
-		g.sceneManager.GoToScene(&TitleScene{
-			meteors: make(map[int]*Meteor),
-			stars:   GenerateStars(numberOfStars),
-		})
+		bindings, err := loadBindings()
+		if err != nil {
+			log.Println("Error loading controls:", err)
+		}
+		g.input.SetBindings(bindings)
+
+		loadScores()
+
+		audio, err := NewAudioManager()
+		if err != nil {
+			return fmt.Errorf("failed to load sounds: %w", err)
+		}
+		g.audio = audio
+
+		g.session = Session{Started: time.Now()}
+		g.sceneManager = &SceneManager{options: &g.options, settings: &g.settings, session: &g.session, audio: g.audio}
+		g.addShutdownHook("scenes", g.sceneManager.shutdown)
+		g.addShutdownHook("audio", g.audio.Close)
+		g.addShutdownHook("session log", func() error { return writeSessionLog(&g.session) })
+
+		g.sceneManager.GoToScene(NewTitleScene(&g.input))
+		if g.options.ReplayFile != "" {
+			r, err := loadReplay(g.options.ReplayFile)
+			if err != nil {
+				return fmt.Errorf("failed to load replay %s: %w", g.options.ReplayFile, err)
+			}
+			g.sceneManager.Push(NewReplayScene(r, g.audio), Instant{})
+		}
+
+		if g.options.WatchRules {
+			g.rulesWatcher = newRulesWatcher(g.options.TuningFile, g.options.LevelsFile)
+		}
+	}
+
+	if ebiten.IsWindowBeingClosed() {
+		return g.shutdown()
 	}
 
+	g.applySettings()
+
+	if g.rulesWatcher != nil && g.rulesWatcher.Update() {
+		g.reloadRules()
+	}
+	g.toast.Update()
+
 	g.input.Update()
 	if err := g.sceneManager.Update(&g.input); err != nil {
+		if errors.Is(err, ebiten.Termination) {
+			return g.shutdown()
+		}
 		return err
 	}
+	g.audio.UpdateMusic()
 	return nil
 }
 
 func (g *Game) Draw(screen *ebiten.Image) {
-	g.sceneManager.Draw(screen)
+	bounds := screen.Bounds()
+	view := newViewport(bounds.Dx(), bounds.Dy(), &g.settings)
+
+	if b := fitImage(g.view, view); b != g.view {
+		g.view = b
+		g.backdrop = generateBackdrop(newRand(), view.width, view.height)
+	}
+	g.view.Clear()
+
+	if view.extended() {
+		drawStars(g.view, g.backdrop)
+	}
+	g.sceneManager.Draw(g.view, view)
+	g.toast.Draw(g.view, view)
+
+	view.present(screen, g.view)
+}
+
+// applySettings makes changes to the settings take effect straight away.
+func (g *Game) applySettings() {
+	if g.settings.Fullscreen != g.applied.Fullscreen {
+		setFullscreen(g.settings.Fullscreen)
+	}
+	if g.settings.Vsync != g.applied.Vsync {
+		ebiten.SetVsyncEnabled(g.settings.Vsync)
+	}
+	starDensity = g.settings.StarDensity
+
+	master := g.settings.MasterVolume
+	g.audio.SetBusVolume(BusSFX, master*g.settings.SFXVolume)
+	g.audio.SetBusVolume(BusUI, master*g.settings.SFXVolume)
+	g.audio.SetBusVolume(BusMusic, master*g.settings.MusicVolume)
+	for b := range busCount {
+		g.audio.SetBusMuted(b, g.options.Mute)
+	}
+	g.applied = g.settings
+}
+
+// setFullscreen switches fullscreen on or off, hiding the cursor while it is
+// on.
+func setFullscreen(on bool) {
+	ebiten.SetFullscreen(on)
+	if on {
+		ebiten.SetCursorMode(ebiten.CursorModeHidden)
+	} else {
+		ebiten.SetCursorMode(ebiten.CursorModeVisible)
+	}
+}
+
+// reloadRules loads the watched files again. Valid rules are used for new
+// games and handed to any game in progress. Invalid ones are reported on
+// screen and the current rules are kept.
+func (g *Game) reloadRules() {
+	rules, err := g.rulesWatcher.load(g.options.Rules)
+	if err != nil {
+		log.Println("Rejected rules reload:", err)
+		g.toast.Show("RULES NOT RELOADED\n"+err.Error(), errorColor)
+		return
+	}
+
+	changes := g.options.Rules.Diff(rules)
+	if len(changes) == 0 {
+		return
+	}
+	for _, c := range changes {
+		log.Println("Rules changed:", c)
+	}
+
+	g.options.Rules = rules
+	g.sceneManager.applyRules(rules)
+	g.toast.Show(fmt.Sprintf("RULES RELOADED, %d CHANGED", len(changes)), selectedColor)
 }
 
-func (g *Game) Layout(_, _ int) (screenWidth, screenHeight int) {
-	return ScreenWidth, ScreenHeight
+// Layout renders at the full resolution of the window. The scenes are drawn
+// at the logical size and scaled up in Draw.
+func (g *Game) Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeight int) {
+	scale := ebiten.Monitor().DeviceScaleFactor()
+	return int(math.Ceil(float64(outsideWidth) * scale)), int(math.Ceil(float64(outsideHeight) * scale))
 }
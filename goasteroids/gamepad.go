@@ -0,0 +1,132 @@
+package goasteroids
+
+import (
+	"log"
+	"math"
+	"slices"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+const (
+	// menuStickThreshold is how far the left stick has to be pushed before it
+	// moves a menu selection.
+	menuStickThreshold = 0.5
+	triggerDeadzone    = 0.05
+)
+
+// GamepadSettings shapes the analog stick response for steering.
+type GamepadSettings struct {
+	// Deadzone is the fraction of stick travel around the centre that is
+	// ignored.
+	Deadzone float64
+	// Exponent bends the response curve. 1 is linear, larger values give
+	// finer control near the centre.
+	Exponent float64
+	// Sensitivity scales the curve, reaching full rotation speed before the
+	// stick is fully pushed when above 1.
+	Sensitivity float64
+}
+
+func DefaultGamepadSettings() GamepadSettings {
+	return GamepadSettings{
+		Deadzone:    0.2,
+		Exponent:    2,
+		Sensitivity: 1,
+	}
+}
+
+// shapeAxis applies the deadzone, response curve and sensitivity to a raw
+// axis value in [-1, 1].
+func (s GamepadSettings) shapeAxis(v float64) float64 {
+	magnitude := math.Abs(v)
+	if magnitude <= s.Deadzone {
+		return 0
+	}
+
+	magnitude = (magnitude - s.Deadzone) / (1 - s.Deadzone)
+	magnitude = math.Pow(magnitude, s.Exponent) * s.Sensitivity
+	return math.Copysign(min(magnitude, 1), v)
+}
+
+func shapeTrigger(v float64) float64 {
+	if v <= triggerDeadzone {
+		return 0
+	}
+	return min((v-triggerDeadzone)/(1-triggerDeadzone), 1)
+}
+
+// updateGamepads tracks connected controllers. A controller only drives the
+// game after it has joined by pressing start, so a pad left on the sofa
+// cannot steer the ship. Joining happens on release so that the same press
+// does not also confirm a menu.
+func (i *Input) updateGamepads() {
+	i.gamepadIDs = ebiten.AppendGamepadIDs(i.gamepadIDs[:0])
+
+	i.connected = inpututil.AppendJustConnectedGamepadIDs(i.connected[:0])
+	for _, id := range i.connected {
+		log.Printf("Gamepad %d connected: %s", id, ebiten.GamepadName(id))
+	}
+
+	if i.hasGamepad && !slices.Contains(i.gamepadIDs, i.gamepad) {
+		log.Printf("Gamepad %d disconnected", i.gamepad)
+		i.hasGamepad = false
+	}
+
+	if !i.hasGamepad {
+		for _, id := range i.gamepadIDs {
+			if ebiten.IsStandardGamepadLayoutAvailable(id) && inpututil.IsStandardGamepadButtonJustReleased(id, ebiten.StandardGamepadButtonCenterRight) {
+				log.Printf("Gamepad %d joined", id)
+				i.gamepad = id
+				i.hasGamepad = true
+				break
+			}
+		}
+	}
+
+	i.turn = 0
+	i.thrust = 0
+	if !i.hasGamepad {
+		return
+	}
+
+	i.turn = i.settings.shapeAxis(ebiten.StandardGamepadAxisValue(i.gamepad, ebiten.StandardGamepadAxisLeftStickHorizontal))
+	i.thrust = shapeTrigger(ebiten.StandardGamepadButtonValue(i.gamepad, ebiten.StandardGamepadButtonFrontBottomRight))
+}
+
+// stickDirection reports menu navigation from the joined controller's left
+// stick.
+func (i *Input) stickDirection(a Action) bool {
+	if !i.hasGamepad {
+		return false
+	}
+
+	switch a {
+	case ActionUp:
+		return ebiten.StandardGamepadAxisValue(i.gamepad, ebiten.StandardGamepadAxisLeftStickVertical) < -menuStickThreshold
+	case ActionDown:
+		return ebiten.StandardGamepadAxisValue(i.gamepad, ebiten.StandardGamepadAxisLeftStickVertical) > menuStickThreshold
+	case ActionLeft:
+		return ebiten.StandardGamepadAxisValue(i.gamepad, ebiten.StandardGamepadAxisLeftStickHorizontal) < -menuStickThreshold
+	case ActionRight:
+		return ebiten.StandardGamepadAxisValue(i.gamepad, ebiten.StandardGamepadAxisLeftStickHorizontal) > menuStickThreshold
+	}
+	return false
+}
+
+// IsGamepadConnected reports whether any controller with a standard layout is
+// plugged in.
+func (i *Input) IsGamepadConnected() bool {
+	for _, id := range i.gamepadIDs {
+		if ebiten.IsStandardGamepadLayoutAvailable(id) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasGamepad reports whether a controller has joined the game.
+func (i *Input) HasGamepad() bool {
+	return i.hasGamepad
+}
@@ -0,0 +1,179 @@
+package goasteroids
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"go-asteroids/assets"
+	"go-asteroids/sim"
+	"io"
+	"log"
+	"os"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	ghostVersion = 1
+	ghostAlpha   = 0.35
+)
+
+var ghostMagic = [4]byte{'G', 'A', 'G', 'H'}
+
+// GhostFrame is where the ship was on one tick of a run.
+type GhostFrame struct {
+	X, Y, Rotation float32
+	// Hidden is set while the ship was exploding or waiting to respawn.
+	Hidden bool
+}
+
+func newGhostFrame(p *sim.Player) GhostFrame {
+	return GhostFrame{
+		X:        float32(p.Position.X),
+		Y:        float32(p.Position.Y),
+		Rotation: float32(p.Rotation),
+		Hidden:   p.Dying,
+	}
+}
+
+// Ghost is the path the ship took through a run. The ghost of the best run is
+// drawn over later games so the player can race it. It is only ever drawn and
+// never enters the collision space.
+type Ghost struct {
+	Score  int
+	Frames []GhostFrame
+}
+
+// bestGhost caches the ghost of the best run. It is loaded on first use.
+var (
+	bestGhost       *Ghost
+	bestGhostLoaded bool
+)
+
+func getGhostFile() (string, error) {
+	return getDataFile("ghost.bin")
+}
+
+// loadBestGhost returns the ghost of the best run, or nil before any run has
+// been recorded.
+func loadBestGhost() *Ghost {
+	if bestGhostLoaded {
+		return bestGhost
+	}
+	bestGhostLoaded = true
+
+	path, err := getGhostFile()
+	if err != nil {
+		log.Println("Error loading ghost:", err)
+		return nil
+	}
+
+	contents, err := loadSaveFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		log.Println("Error loading ghost:", err)
+		return nil
+	}
+
+	bestGhost, err = readGhost(contents)
+	if err != nil {
+		log.Println("Error loading ghost:", err)
+	}
+	return bestGhost
+}
+
+// offerGhost keeps g as the best ghost if it scored higher than the current
+// one.
+func offerGhost(g *Ghost) {
+	if best := loadBestGhost(); best != nil && best.Score >= g.Score {
+		return
+	}
+	bestGhost = g
+
+	path, err := getGhostFile()
+	if err != nil {
+		log.Println("Error saving ghost:", err)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := g.write(&buf); err != nil {
+		log.Println("Error saving ghost:", err)
+		return
+	}
+
+	if err := writeSaveFile(path, buf.Bytes()); err != nil {
+		log.Println("Error saving ghost:", err)
+	}
+}
+
+func (g *Ghost) write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	header := make([]byte, 0, 4+2+8+4)
+	header = append(header, ghostMagic[:]...)
+	header = binary.LittleEndian.AppendUint16(header, ghostVersion)
+	header = binary.LittleEndian.AppendUint64(header, uint64(g.Score))
+	header = binary.LittleEndian.AppendUint32(header, uint32(len(g.Frames)))
+	if _, err := bw.Write(header); err != nil {
+		return err
+	}
+
+	if err := binary.Write(bw, binary.LittleEndian, g.Frames); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// readGhost decodes a ghost written by write. The frame count is checked
+// against the length of data before anything is allocated for the frames.
+func readGhost(data []byte) (*Ghost, error) {
+	const headerSize = 4 + 2 + 8 + 4
+	if len(data) < headerSize {
+		return nil, errors.New("failed to read ghost header: file is too short")
+	}
+	header := data[:headerSize]
+	if [4]byte(header[:4]) != ghostMagic {
+		return nil, errors.New("not a ghost file")
+	}
+	if v := binary.LittleEndian.Uint16(header[4:]); v != ghostVersion {
+		return nil, fmt.Errorf("unsupported ghost file version %d", v)
+	}
+
+	count := binary.LittleEndian.Uint32(header[14:])
+	if want := uint64(count) * uint64(binary.Size(GhostFrame{})); want != uint64(len(data)-headerSize) {
+		return nil, fmt.Errorf("ghost of %d frames does not match its %d bytes of frames", count, len(data)-headerSize)
+	}
+
+	g := &Ghost{
+		Score:  int(binary.LittleEndian.Uint64(header[6:])),
+		Frames: make([]GhostFrame, count),
+	}
+	if err := binary.Read(bytes.NewReader(data[headerSize:]), binary.LittleEndian, g.Frames); err != nil {
+		return nil, fmt.Errorf("failed to read ghost frames: %w", err)
+	}
+	return g, nil
+}
+
+// drawGhost draws the ghost ship as it was on the given tick of its run.
+func drawGhost(screen *ebiten.Image, g *Ghost, tick int) {
+	if tick < 0 || tick >= len(g.Frames) || g.Frames[tick].Hidden {
+		return
+	}
+	f := g.Frames[tick]
+
+	halfW, halfH := HalfOfTheImage(assets.PlayerSprite)
+
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(-halfW, -halfH)
+	op.GeoM.Rotate(float64(f.Rotation))
+	op.GeoM.Translate(halfW, halfH)
+	op.GeoM.Translate(float64(f.X), float64(f.Y))
+	op.ColorScale.ScaleAlpha(ghostAlpha)
+
+	screen.DrawImage(assets.PlayerSprite, op)
+}
@@ -0,0 +1,209 @@
+package goasteroids
+
+import (
+	"go-asteroids/sim"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Action is something the player can ask the game to do, independent of the
+// device and key used to ask for it.
+type Action int
+
+const (
+	ActionRotateLeft Action = iota
+	ActionRotateRight
+	ActionThrust
+	ActionReverse
+	ActionFire
+	ActionShield
+	ActionHyperSpace
+	ActionPause
+	ActionConfirm
+	ActionBack
+	ActionUp
+	ActionDown
+	ActionLeft
+	ActionRight
+	ActionNewGame
+	ActionControls
+	ActionOptions
+	ActionReplay
+	ActionQuit
+	actionCount
+)
+
+var actionNames = [actionCount]string{
+	ActionRotateLeft:  "rotate-left",
+	ActionRotateRight: "rotate-right",
+	ActionThrust:      "thrust",
+	ActionReverse:     "reverse",
+	ActionFire:        "fire",
+	ActionShield:      "shield",
+	ActionHyperSpace:  "hyperspace",
+	ActionPause:       "pause",
+	ActionConfirm:     "confirm",
+	ActionBack:        "back",
+	ActionUp:          "menu-up",
+	ActionDown:        "menu-down",
+	ActionLeft:        "menu-left",
+	ActionRight:       "menu-right",
+	ActionNewGame:     "new-game",
+	ActionControls:    "controls",
+	ActionOptions:     "options",
+	ActionReplay:      "replay",
+	ActionQuit:        "quit",
+}
+
+var actionLabels = [actionCount]string{
+	ActionRotateLeft:  "ROTATE LEFT",
+	ActionRotateRight: "ROTATE RIGHT",
+	ActionThrust:      "THRUST",
+	ActionReverse:     "REVERSE",
+	ActionFire:        "FIRE",
+	ActionShield:      "SHIELD",
+	ActionHyperSpace:  "HYPERSPACE",
+	ActionPause:       "PAUSE",
+	ActionConfirm:     "CONFIRM",
+	ActionBack:        "BACK",
+	ActionUp:          "MENU UP",
+	ActionDown:        "MENU DOWN",
+	ActionLeft:        "MENU LEFT",
+	ActionRight:       "MENU RIGHT",
+	ActionNewGame:     "NEW GAME",
+	ActionControls:    "CONTROLS MENU",
+	ActionOptions:     "OPTIONS MENU",
+	ActionReplay:      "WATCH REPLAY",
+	ActionQuit:        "QUIT",
+}
+
+// Name is the stable identifier used for the action in the controls file.
+func (a Action) Name() string {
+	return actionNames[a]
+}
+
+// Label is the text shown for the action in menus.
+func (a Action) Label() string {
+	return actionLabels[a]
+}
+
+// isMenu reports whether the action is used in menus.
+func (a Action) isMenu() bool {
+	return a >= ActionConfirm
+}
+
+// inGameplay reports whether the action is checked while a game is played:
+// the ship controls, pause and quit, which leaves the game for the title.
+func (a Action) inGameplay() bool {
+	return a < ActionConfirm || a == ActionQuit
+}
+
+// sharesContext reports whether a and other can be checked on the same tick,
+// so that they must not share a key or button.
+func (a Action) sharesContext(other Action) bool {
+	return a.isMenu() && other.isMenu() || a.inGameplay() && other.inGameplay()
+}
+
+// Input turns the raw keyboard and gamepad state into per-tick action state.
+// It is updated once per tick by Game and handed to scenes through State.
+type Input struct {
+	bindings   Bindings
+	settings   GamepadSettings
+	gamepadIDs []ebiten.GamepadID
+	connected  []ebiten.GamepadID
+	gamepad    ebiten.GamepadID
+	hasGamepad bool
+	pressed    [actionCount]bool
+	previous   [actionCount]bool
+	turn       float64
+	thrust     float64
+}
+
+func (i *Input) Update() {
+	if i.bindings == nil {
+		i.bindings = DefaultBindings()
+	}
+	if i.settings == (GamepadSettings{}) {
+		i.settings = DefaultGamepadSettings()
+	}
+
+	i.updateGamepads()
+
+	i.previous = i.pressed
+	for a := range actionCount {
+		i.pressed[a] = i.stickDirection(a)
+		for _, b := range i.bindings[a] {
+			if i.isBindingPressed(b) {
+				i.pressed[a] = true
+				break
+			}
+		}
+	}
+}
+
+func (i *Input) isBindingPressed(b Binding) bool {
+	if !b.Gamepad {
+		return ebiten.IsKeyPressed(b.Key)
+	}
+	return i.hasGamepad && ebiten.IsStandardGamepadButtonPressed(i.gamepad, b.Button)
+}
+
+// Bindings returns the bindings currently in use.
+func (i *Input) Bindings() Bindings {
+	if i.bindings == nil {
+		i.bindings = DefaultBindings()
+	}
+	return i.bindings
+}
+
+// SetBindings replaces the bindings, taking effect on the next Update.
+func (i *Input) SetBindings(b Bindings) {
+	i.bindings = b
+}
+
+// Prompt returns the label of the key or button on-screen prompts should ask
+// for to trigger a: the first gamepad button bound to it once a controller
+// has joined, otherwise the first key, and failing either whatever it is
+// bound to.
+func (i *Input) Prompt(a Action) string {
+	bindings := i.Bindings()[a]
+	for _, b := range bindings {
+		if b.Gamepad == i.hasGamepad {
+			return b.Label()
+		}
+	}
+	if len(bindings) > 0 {
+		return bindings[0].Label()
+	}
+	return "---"
+}
+
+// IsPressed reports whether the action is held down this tick.
+func (i *Input) IsPressed(a Action) bool {
+	return i.pressed[a]
+}
+
+// IsJustPressed reports whether the action started this tick.
+func (i *Input) IsJustPressed(a Action) bool {
+	return i.pressed[a] && !i.previous[a]
+}
+
+// IsJustReleased reports whether the action stopped this tick.
+func (i *Input) IsJustReleased(a Action) bool {
+	return !i.pressed[a] && i.previous[a]
+}
+
+// PlayerInput returns the ship controls for the simulation.
+func (i *Input) PlayerInput() sim.Input {
+	return sim.Input{
+		RotateLeft:   i.IsPressed(ActionRotateLeft),
+		RotateRight:  i.IsPressed(ActionRotateRight),
+		Thrust:       i.IsPressed(ActionThrust),
+		Reverse:      i.IsPressed(ActionReverse),
+		Fire:         i.IsPressed(ActionFire),
+		Shield:       i.IsPressed(ActionShield),
+		HyperSpace:   i.IsPressed(ActionHyperSpace),
+		Turn:         i.turn,
+		ThrustAmount: i.thrust,
+	}
+}
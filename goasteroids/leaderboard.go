@@ -0,0 +1,178 @@
+package goasteroids
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	leaderboardVersion  = 1
+	leaderboardSize     = 10
+	initialsLength      = 3
+	legacyInitials      = "???"
+	leaderboardFileName = "scores.json"
+	legacyHighScoreFile = "highscore.txt"
+)
+
+// ScoreEntry is one row of the leaderboard.
+type ScoreEntry struct {
+	Initials string    `json:"initials"`
+	Score    int       `json:"score"`
+	Level    int       `json:"level"`
+	Date     time.Time `json:"date"`
+	// Seconds is how long the game was played, not counting menus and the
+	// screens between levels.
+	Seconds int `json:"seconds"`
+}
+
+// Duration formats the play time as minutes and seconds.
+func (e ScoreEntry) Duration() string {
+	return fmt.Sprintf("%d:%02d", e.Seconds/60, e.Seconds%60)
+}
+
+// Leaderboard holds the best scores, highest first.
+type Leaderboard struct {
+	Entries []ScoreEntry
+}
+
+// Best returns the top score, or 0 for an empty leaderboard.
+func (l *Leaderboard) Best() int {
+	if len(l.Entries) == 0 {
+		return 0
+	}
+	return l.Entries[0].Score
+}
+
+// Qualifies reports whether score would make it onto the leaderboard.
+func (l *Leaderboard) Qualifies(score int) bool {
+	if score <= 0 {
+		return false
+	}
+	return len(l.Entries) < leaderboardSize || score > l.Entries[len(l.Entries)-1].Score
+}
+
+// Insert adds e below any entries with the same score and returns its rank,
+// starting at 0. Entries pushed past the end of the leaderboard are dropped.
+func (l *Leaderboard) Insert(e ScoreEntry) int {
+	rank := len(l.Entries)
+	for i, other := range l.Entries {
+		if e.Score > other.Score {
+			rank = i
+			break
+		}
+	}
+
+	l.Entries = slices.Insert(l.Entries, rank, e)
+	if len(l.Entries) > leaderboardSize {
+		l.Entries = l.Entries[:leaderboardSize]
+	}
+	if rank >= leaderboardSize {
+		return -1
+	}
+	return rank
+}
+
+type leaderboardFile struct {
+	Version int          `json:"version"`
+	Entries []ScoreEntry `json:"entries"`
+}
+
+// loadLeaderboard reads the saved scores. The first time it runs it carries
+// over the single score from the old highscore.txt file.
+func loadLeaderboard() (*Leaderboard, error) {
+	l := &Leaderboard{}
+
+	path, err := getDataFile(leaderboardFileName)
+	if err != nil {
+		return l, err
+	}
+
+	contents, err := loadSaveFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return migrateHighScore()
+	}
+	if err != nil {
+		return l, fmt.Errorf("failed to read leaderboard file: %w", err)
+	}
+
+	var f leaderboardFile
+	if err := json.Unmarshal(contents, &f); err != nil {
+		return l, fmt.Errorf("failed to parse leaderboard file: %w", err)
+	}
+	if f.Version != leaderboardVersion {
+		return l, fmt.Errorf("unsupported leaderboard file version %d", f.Version)
+	}
+
+	for _, e := range f.Entries {
+		l.Insert(e)
+	}
+	return l, nil
+}
+
+// migrateHighScore builds a leaderboard from the legacy highscore.txt, which
+// only ever stored a score.
+func migrateHighScore() (*Leaderboard, error) {
+	l := &Leaderboard{}
+
+	path, err := getDataFile(legacyHighScoreFile)
+	if err != nil {
+		return l, err
+	}
+
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return l, nil
+	}
+	if err != nil {
+		return l, fmt.Errorf("failed to read highscore file: %w", err)
+	}
+
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return l, fmt.Errorf("failed to read highscore file: %w", err)
+	}
+
+	score, err := strconv.Atoi(strings.TrimSpace(string(contents)))
+	if err != nil {
+		return l, fmt.Errorf("failed to convert highscore to integer: %w", err)
+	}
+
+	if score > 0 {
+		l.Insert(ScoreEntry{
+			Initials: legacyInitials,
+			Score:    score,
+			Date:     info.ModTime(),
+		})
+	}
+
+	if err := saveLeaderboard(l); err != nil {
+		return l, err
+	}
+	return l, nil
+}
+
+func saveLeaderboard(l *Leaderboard) error {
+	path, err := getDataFile(leaderboardFileName)
+	if err != nil {
+		return err
+	}
+
+	contents, err := json.MarshalIndent(leaderboardFile{
+		Version: leaderboardVersion,
+		Entries: l.Entries,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode leaderboard: %w", err)
+	}
+
+	if err := writeSaveFile(path, contents); err != nil {
+		return fmt.Errorf("failed to write leaderboard file: %w", err)
+	}
+	return nil
+}
@@ -3,10 +3,10 @@ package goasteroids
 import (
 	"fmt"
 	"go-asteroids/assets"
+	"go-asteroids/sim"
 	"image/color"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/hajimehoshi/ebiten/v2/text/v2"
 )
 
@@ -17,11 +17,9 @@ type LevelStartScene struct {
 }
 
 func (l *LevelStartScene) Draw(screen *ebiten.Image) {
-	for _, s := range l.stars {
-		s.Draw(screen)
-	}
+	drawStars(screen, l.stars)
 
-	textToDraw := fmt.Sprintf("Level %d", l.game.currentLevel)
+	textToDraw := fmt.Sprintf("Level %d", l.game.world.CurrentLevel)
 	op := &text.DrawOptions{
 		LayoutOptions: text.LayoutOptions{
 			PrimaryAlign: text.AlignCenter,
@@ -33,29 +31,42 @@ func (l *LevelStartScene) Draw(screen *ebiten.Image) {
 		Source: assets.TitleFont,
 		Size:   48,
 	}, op)
+
+	level := l.game.world.Level()
+	y := ScreenHeight/2 + 80.0
+	if level.ExtraLife {
+		drawText(screen, "EXTRA LIFE", assets.ScoreFont, 16, ScreenWidth/2, y, text.AlignCenter, selectedColor)
+		y += 30
+	}
+	if level.BonusScore > 0 {
+		drawText(screen, fmt.Sprintf("BONUS %d", level.BonusScore), assets.ScoreFont, 16, ScreenWidth/2, y, text.AlignCenter, selectedColor)
+	}
+}
+
+func (l *LevelStartScene) applyRules(rules sim.Rules) {
+	l.game.applyRules(rules)
 }
 
+// OnShutdown starts the next level early so that the game is suspended ready
+// to carry on with it. Once the level has started the game is on its way in
+// and saves itself, so the slot is only written once.
+func (l *LevelStartScene) OnShutdown() error {
+	if l.game.world.Phase != sim.PhaseLevelComplete {
+		return nil
+	}
+	l.game.world.StartNextLevel()
+	return l.game.OnShutdown()
+}
+
+// music keeps the gameplay music going between levels.
+func (l *LevelStartScene) music() MusicTrack { return MusicGameplay }
+
 func (l *LevelStartScene) Update(state *State) error {
 	l.nextLevelTimer.Update()
-	if l.nextLevelTimer.IsReady() {
-		l.game.meteorsForLevel += 2
-		l.game.meteorCount = 0
-		for k, v := range l.game.lasers {
-			delete(l.game.lasers, k)
-			l.game.space.Remove(v.laserObj)
-		}
-		state.SceneManager.GoToScene(l.game)
-	}
-	
-	if inpututil.IsKeyJustPressed(ebiten.KeySpace){
-		l.game.meteorsForLevel += 2
-		l.game.meteorCount = 0
-		for k, v := range l.game.lasers {
-			delete(l.game.lasers, k)
-			l.game.space.Remove(v.laserObj)
-		}
-		state.SceneManager.GoToScene(l.game)
+	if l.nextLevelTimer.IsReady() || state.Input.IsJustPressed(ActionConfirm) {
+		l.game.world.StartNextLevel()
+		state.SceneManager.GoToScene(l.game, Iris{Length: defaultTransitionTicks})
 	}
-	
+
 	return nil
 }
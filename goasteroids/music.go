@@ -0,0 +1,260 @@
+package goasteroids
+
+import (
+	"bytes"
+	"log"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2/audio"
+)
+
+// MusicTrack is a loop of music played behind a scene.
+type MusicTrack int
+
+const (
+	MusicNone MusicTrack = iota
+	MusicTitle
+	MusicGameplay
+	MusicGameOver
+	musicTrackCount
+)
+
+// Stem is a layer of a track that can be faded in and out while the track
+// plays. Tracks with a single stem only have StemBase.
+type Stem int
+
+const (
+	// StemBase always plays.
+	StemBase Stem = iota
+	// StemDrive grows with the number of meteors in the gameplay music.
+	StemDrive
+	// StemAlien plays in the gameplay music while aliens are about.
+	StemAlien
+	stemCount
+)
+
+// stemFadeStep is how far the level of a stem moves towards the level asked
+// for each tick, so that the music swells rather than jumps.
+const stemFadeStep = 1.0 / 60
+
+// chord is a root note to play in the bass and the notes to build the rest
+// of the harmony from, as MIDI note numbers.
+type chord struct {
+	root  int
+	tones []int
+}
+
+var (
+	aMinor = chord{root: 45, tones: []int{57, 60, 64}}
+	dMinor = chord{root: 38, tones: []int{53, 57, 62}}
+	cMajor = chord{root: 48, tones: []int{55, 60, 64}}
+	eMajor = chord{root: 40, tones: []int{56, 59, 64}}
+	fMajor = chord{root: 41, tones: []int{53, 57, 60}}
+	gMajor = chord{root: 43, tones: []int{55, 59, 62}}
+)
+
+// pads holds every chord for beatsEach beats.
+func pads(chords []chord, beatsEach float64) []note {
+	var notes []note
+	for i, c := range chords {
+		for _, t := range c.tones {
+			notes = append(notes, note{beat: float64(i) * beatsEach, length: beatsEach, pitch: t})
+		}
+	}
+	return notes
+}
+
+// bassLine plays the root of every chord in eighth notes, an octave up on
+// the offbeats.
+func bassLine(chords []chord, beatsEach float64) []note {
+	var notes []note
+	for i, c := range chords {
+		for j := range int(beatsEach * 2) {
+			pitch := c.root
+			if j%2 == 1 {
+				pitch += 12
+			}
+			notes = append(notes, note{beat: float64(i)*beatsEach + float64(j)/2, length: 0.4, pitch: pitch})
+		}
+	}
+	return notes
+}
+
+// arpeggio runs up and down the tones of every chord, step beats a note,
+// raised by shift semitones.
+func arpeggio(chords []chord, beatsEach, step float64, shift int) []note {
+	order := []int{0, 1, 2, 1}
+	var notes []note
+	for i, c := range chords {
+		for j := range int(beatsEach / step) {
+			pitch := c.tones[order[j%len(order)]] + shift
+			notes = append(notes, note{beat: float64(i)*beatsEach + float64(j)*step, length: step, pitch: pitch})
+		}
+	}
+	return notes
+}
+
+// siren wavers between two octaves above the root of every chord and the
+// tritone above that, step beats each.
+func siren(chords []chord, beatsEach, step float64) []note {
+	var notes []note
+	for i, c := range chords {
+		for j := range int(beatsEach / step) {
+			pitch := c.root + 24
+			if j%2 == 1 {
+				pitch += 6
+			}
+			notes = append(notes, note{beat: float64(i)*beatsEach + float64(j)*step, length: step, pitch: pitch})
+		}
+	}
+	return notes
+}
+
+var (
+	titleChords    = []chord{aMinor, fMajor, cMajor, gMajor}
+	gameplayChords = []chord{aMinor, fMajor, cMajor, gMajor}
+	gameOverChords = []chord{aMinor, dMinor, eMajor, aMinor}
+
+	padInstrument  = instrument{wave: triangle, attack: 0.8, release: 1.2, volume: 0.1}
+	bellInstrument = instrument{wave: sine, attack: 0.01, decay: 0.6, release: 0.3, volume: 0.08}
+)
+
+// musicScores are the tracks, synthesised when the game starts. The gameplay
+// track runs at a tempo the heartbeat keeps time with.
+var musicScores = [musicTrackCount]score{
+	MusicTitle: {bpm: 96, beats: 32, stems: [][]part{
+		StemBase: {
+			{instrument: padInstrument, notes: pads(titleChords, 8)},
+			{instrument: bellInstrument, notes: arpeggio(titleChords, 8, 1, 12)},
+		},
+	}},
+	MusicGameplay: {bpm: 150, beats: 32, stems: [][]part{
+		StemBase: {{
+			instrument: instrument{wave: square, attack: 0.005, decay: 0.15, release: 0.05, volume: 0.3},
+			notes:      bassLine(gameplayChords, 8),
+		}},
+		StemDrive: {{
+			instrument: instrument{wave: triangle, attack: 0.005, decay: 0.08, release: 0.05, volume: 0.15},
+			notes:      arpeggio(gameplayChords, 8, 0.25, 12),
+		}},
+		StemAlien: {{
+			instrument: instrument{wave: sine, attack: 0.3, release: 0.3, detune: 0.3, volume: 0.1},
+			notes:      siren(gameplayChords, 8, 2),
+		}},
+	}},
+	MusicGameOver: {bpm: 72, beats: 16, stems: [][]part{
+		StemBase: {
+			{instrument: padInstrument, notes: pads(gameOverChords, 4)},
+			{instrument: bellInstrument, notes: []note{
+				{beat: 0, length: 2, pitch: 76}, {beat: 2, length: 2, pitch: 72},
+				{beat: 4, length: 2, pitch: 74}, {beat: 6, length: 2, pitch: 69},
+				{beat: 8, length: 2, pitch: 71}, {beat: 10, length: 2, pitch: 68},
+				{beat: 12, length: 4, pitch: 69},
+			}},
+		},
+	}},
+}
+
+// trackPlayer plays the stems of a track in step. Its volume moves by
+// fadeStep every tick, down to silence while it is being left.
+type trackPlayer struct {
+	score    score
+	stems    []*audio.Player
+	volume   float64
+	fadeStep float64
+}
+
+func (a *AudioManager) loadMusic() error {
+	for track, s := range musicScores {
+		if len(s.stems) == 0 {
+			continue
+		}
+
+		t := &trackPlayer{score: s}
+		for _, pcm := range s.render() {
+			p, err := a.context.NewPlayer(audio.NewInfiniteLoop(bytes.NewReader(pcm), int64(len(pcm))))
+			if err != nil {
+				return err
+			}
+			t.stems = append(t.stems, p)
+		}
+		a.tracks[track] = t
+	}
+	a.stemLevels[StemBase] = 1
+	a.stemTargets[StemBase] = 1
+	return nil
+}
+
+// PlayMusic crossfades from the track playing to track over fadeTicks. A
+// track that is still fading out picks up from where it has got to.
+func (a *AudioManager) PlayMusic(track MusicTrack, fadeTicks int) {
+	if track == a.music {
+		return
+	}
+	step := 1 / float64(max(fadeTicks, 1))
+
+	if t := a.tracks[a.music]; t != nil {
+		t.fadeStep = -step
+	}
+	a.music = track
+
+	t := a.tracks[track]
+	if t == nil {
+		return
+	}
+	if t.volume == 0 {
+		for _, p := range t.stems {
+			p.SetVolume(0)
+			if err := p.Rewind(); err != nil {
+				log.Println("Error rewinding music:", err)
+			}
+			p.Play()
+		}
+	}
+	t.fadeStep = step
+}
+
+// SetStemLevel sets how loud the stem of the track playing should be, from
+// 0 to 1. The stem gets there over about a second.
+func (a *AudioManager) SetStemLevel(s Stem, level float64) {
+	a.stemTargets[s] = max(0, min(level, 1))
+}
+
+// UpdateMusic moves the fades and stem levels on by a tick.
+func (a *AudioManager) UpdateMusic() {
+	for s := range a.stemLevels {
+		if a.stemLevels[s] < a.stemTargets[s] {
+			a.stemLevels[s] = min(a.stemLevels[s]+stemFadeStep, a.stemTargets[s])
+		} else {
+			a.stemLevels[s] = max(a.stemLevels[s]-stemFadeStep, a.stemTargets[s])
+		}
+	}
+
+	bus := a.busVolume(BusMusic)
+	for _, t := range a.tracks {
+		if t == nil || t.volume == 0 && t.fadeStep <= 0 {
+			continue
+		}
+
+		t.volume = max(0, min(t.volume+t.fadeStep, 1))
+		for s, p := range t.stems {
+			if t.volume == 0 {
+				p.Pause()
+				continue
+			}
+			p.SetVolume(bus * t.volume * a.stemLevels[s])
+		}
+	}
+}
+
+// MusicBeat returns the number of the beat the music playing has reached and
+// how long its beats are, so that other sounds can keep time with it. It
+// reports false when there is no music playing.
+func (a *AudioManager) MusicBeat() (beat int, length time.Duration, ok bool) {
+	t := a.tracks[a.music]
+	if t == nil || !t.stems[0].IsPlaying() {
+		return 0, 0, false
+	}
+	length = time.Duration(t.score.beatLength() * float64(time.Second))
+	return int(t.stems[0].Position() / length), length, true
+}
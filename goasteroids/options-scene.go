@@ -0,0 +1,193 @@
+package goasteroids
+
+import (
+	"fmt"
+	"go-asteroids/assets"
+	"image/color"
+	"log"
+	"math"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+)
+
+// levelStep is how far left and right move a level setting.
+const levelStep = 0.1
+
+// optionsRow is a line of the options menu. adjust changes the setting it
+// shows, with dir -1 for left and 1 for right or confirm. Rows without a
+// setting run action on confirm instead.
+type optionsRow struct {
+	label  string
+	value  func(s *Settings) string
+	adjust func(s *Settings, dir int)
+	action func(o *OptionsScene, state *State)
+}
+
+func toggleRow(label string, field func(s *Settings) *bool) optionsRow {
+	return optionsRow{
+		label:  label,
+		value:  func(s *Settings) string { return onOff(*field(s)) },
+		adjust: func(s *Settings, _ int) { *field(s) = !*field(s) },
+	}
+}
+
+func levelRow(label string, field func(s *Settings) *float64) optionsRow {
+	return optionsRow{
+		label: label,
+		value: func(s *Settings) string { return fmt.Sprintf("%d%%", int(math.Round(*field(s)*100))) },
+		adjust: func(s *Settings, dir int) {
+			v := field(s)
+			*v = max(0, min(math.Round((*v+float64(dir)*levelStep)*10)/10, 1))
+		},
+	}
+}
+
+var optionsRows = []optionsRow{
+	toggleRow("BEST RUN GHOST", func(s *Settings) *bool { return &s.Ghost }),
+	levelRow("MASTER VOLUME", func(s *Settings) *float64 { return &s.MasterVolume }),
+	levelRow("SFX VOLUME", func(s *Settings) *float64 { return &s.SFXVolume }),
+	levelRow("MUSIC VOLUME", func(s *Settings) *float64 { return &s.MusicVolume }),
+	toggleRow("FULLSCREEN", func(s *Settings) *bool { return &s.Fullscreen }),
+	toggleRow("VSYNC", func(s *Settings) *bool { return &s.Vsync }),
+	{
+		label: "WIDE SCREENS",
+		value: func(s *Settings) string { return strings.ToUpper(aspectModeNames[s.Aspect]) },
+		adjust: func(s *Settings, _ int) {
+			s.Aspect = (s.Aspect + 1) % AspectMode(len(aspectModeNames))
+		},
+	},
+	{
+		label: "SCALING",
+		value: func(s *Settings) string { return strings.ToUpper(scaleModeNames[s.Scaling]) },
+		adjust: func(s *Settings, _ int) {
+			s.Scaling = (s.Scaling + 1) % ScaleMode(len(scaleModeNames))
+		},
+	},
+	levelRow("SCREEN SHAKE", func(s *Settings) *float64 { return &s.ShakeIntensity }),
+	levelRow("STARFIELD", func(s *Settings) *float64 { return &s.StarDensity }),
+	levelRow("HUD OPACITY", func(s *Settings) *float64 { return &s.HUDOpacity }),
+	{
+		label: "CONTROLS",
+		action: func(_ *OptionsScene, state *State) {
+			state.SceneManager.Push(NewControlsScene(state.Input.Bindings().Clone()))
+		},
+	},
+	{
+		label: "SAVE AND RETURN",
+		action: func(o *OptionsScene, state *State) {
+			o.leave(state)
+		},
+	},
+}
+
+// OptionsScene is pushed over the title or pause menu. It changes the
+// settings in place, so that they apply straight away, and saves them when
+// popped.
+type OptionsScene struct {
+	settings *Settings
+	// opened is the settings as they were when the scene was pushed, so
+	// that only what is changed here is saved.
+	opened   Settings
+	selected int
+	stars    []*Star
+}
+
+func NewOptionsScene(settings *Settings) *OptionsScene {
+	return &OptionsScene{
+		settings: settings,
+		opened:   *settings,
+		stars:    GenerateStars(newRand(), numberOfStars),
+	}
+}
+
+func (o *OptionsScene) Update(state *State) error {
+	if state.Input.IsJustPressed(ActionUp) {
+		o.selected = (o.selected + len(optionsRows) - 1) % len(optionsRows)
+	}
+
+	if state.Input.IsJustPressed(ActionDown) {
+		o.selected = (o.selected + 1) % len(optionsRows)
+	}
+
+	if state.Input.IsJustPressed(ActionBack) {
+		o.leave(state)
+		return nil
+	}
+
+	row := optionsRows[o.selected]
+	if row.action != nil {
+		if state.Input.IsJustPressed(ActionConfirm) {
+			row.action(o, state)
+		}
+		return nil
+	}
+
+	switch {
+	case state.Input.IsJustPressed(ActionLeft):
+		row.adjust(o.settings, -1)
+	case state.Input.IsJustPressed(ActionRight), state.Input.IsJustPressed(ActionConfirm):
+		row.adjust(o.settings, 1)
+	}
+
+	return nil
+}
+
+func (o *OptionsScene) leave(state *State) {
+	if err := o.OnShutdown(); err != nil {
+		log.Println("Error saving settings:", err)
+	}
+	state.SceneManager.Pop()
+}
+
+// OnShutdown saves the settings, as leaving the scene would. Fullscreen and
+// vsync may be overridden on the command line for the session, so unless
+// they were changed here they keep the values already saved.
+func (o *OptionsScene) OnShutdown() error {
+	s := *o.settings
+	saved, err := loadSettings()
+	if err != nil {
+		log.Println("Error loading settings:", err)
+		saved = s
+	}
+
+	if s.Fullscreen == o.opened.Fullscreen {
+		s.Fullscreen = saved.Fullscreen
+	}
+	if s.Vsync == o.opened.Vsync {
+		s.Vsync = saved.Vsync
+	}
+	return saveSettings(s)
+}
+
+func (o *OptionsScene) Draw(screen *ebiten.Image) {
+	drawStars(screen, o.stars)
+
+	drawText(screen, "OPTIONS", assets.TitleFont, 48, ScreenWidth/2, 60, text.AlignCenter, color.White)
+
+	y := 150.0
+	for i, row := range optionsRows {
+		clr := color.Color(color.White)
+		if i == o.selected {
+			clr = selectedColor
+		}
+
+		if row.value == nil {
+			drawText(screen, row.label, assets.ScoreFont, 16, ScreenWidth/2, y, text.AlignCenter, clr)
+		} else {
+			drawText(screen, row.label, assets.ScoreFont, 16, ScreenWidth/2-40, y, text.AlignEnd, clr)
+			drawText(screen, row.value(o.settings), assets.ScoreFont, 16, ScreenWidth/2+40, y, text.AlignStart, clr)
+		}
+		y += 28
+	}
+
+	drawText(screen, "LEFT AND RIGHT TO CHANGE", assets.ScoreFont, 12, ScreenWidth/2, ScreenHeight-40, text.AlignCenter, color.Gray{Y: 0x80})
+}
+
+func onOff(b bool) string {
+	if b {
+		return "ON"
+	}
+	return "OFF"
+}
@@ -0,0 +1,52 @@
+package goasteroids
+
+import (
+	"go-asteroids/sim"
+	"math/rand/v2"
+)
+
+// Options configure a Game. DefaultOptions is a normal play session.
+type Options struct {
+	// Seed fixes the seed of every game so runs can be reproduced. Zero picks
+	// a fresh random seed for each game.
+	Seed uint64
+	// Rules are the tuning and levels new games are played with.
+	Rules sim.Rules
+	// TuningFile and LevelsFile are where Rules were loaded from. An empty
+	// path means the embedded defaults.
+	TuningFile string
+	LevelsFile string
+	// WatchRules reloads TuningFile and LevelsFile whenever they change and
+	// applies them to the game in progress.
+	WatchRules bool
+	// Mute silences every sound.
+	Mute bool
+	// ReplayFile is a replay to play instead of showing the title.
+	ReplayFile string
+	// Fullscreen and Vsync, when set, replace the saved settings for this
+	// session.
+	Fullscreen *bool
+	Vsync      *bool
+	// DataDir keeps saves, settings and replays in this directory instead of
+	// the usual app data directory.
+	DataDir string
+}
+
+func DefaultOptions() Options {
+	return Options{
+		Rules: sim.DefaultRules(),
+	}
+}
+
+func (o *Options) gameSeed() uint64 {
+	if o.Seed != 0 {
+		return o.Seed
+	}
+	return rand.Uint64()
+}
+
+// newRand returns an unseeded generator for cosmetic randomness outside of a
+// game, such as the menu backgrounds.
+func newRand() *rand.Rand {
+	return rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+}
@@ -0,0 +1,58 @@
+package goasteroids
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"sync/atomic"
+)
+
+// bytesPerFrame is the size of one stereo frame of 16-bit samples, the format
+// the decoded sounds and the audio context share.
+const bytesPerFrame = 4
+
+// panStream moves a stereo sound between the speakers by turning down the
+// side it is panned away from. The pan is changed by the game while the audio
+// goroutine reads the stream, so it is held atomically.
+type panStream struct {
+	src io.ReadSeeker
+	pan atomic.Uint64
+}
+
+func newPanStream(src io.ReadSeeker) *panStream {
+	return &panStream{src: src}
+}
+
+// setPan sets the pan from -1 for the left speaker alone to 1 for the right.
+func (s *panStream) setPan(pan float64) {
+	s.pan.Store(math.Float64bits(max(-1, min(pan, 1))))
+}
+
+// Read reads whole frames only, so that every sample is scaled by the gain
+// of its own channel.
+func (s *panStream) Read(p []byte) (int, error) {
+	if len(p) < bytesPerFrame {
+		return 0, io.ErrShortBuffer
+	}
+	n, err := s.src.Read(p[:len(p)-len(p)%bytesPerFrame])
+
+	pan := math.Float64frombits(s.pan.Load())
+	if pan == 0 {
+		return n, err
+	}
+	left, right := min(1, 1-pan), min(1, 1+pan)
+	for i := 0; i+bytesPerFrame <= n; i += bytesPerFrame {
+		scaleSample(p[i:], left)
+		scaleSample(p[i+2:], right)
+	}
+	return n, err
+}
+
+func (s *panStream) Seek(offset int64, whence int) (int64, error) {
+	return s.src.Seek(offset, whence)
+}
+
+func scaleSample(b []byte, gain float64) {
+	v := int16(binary.LittleEndian.Uint16(b))
+	binary.LittleEndian.PutUint16(b, uint16(int16(float64(v)*gain)))
+}
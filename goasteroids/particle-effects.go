@@ -0,0 +1,143 @@
+package goasteroids
+
+import (
+	"go-asteroids/sim"
+	"math"
+	"math/rand/v2"
+)
+
+var (
+	debrisStyle = &particleStyle{
+		color: gradient{{R: 0xb0, G: 0xa0, B: 0x90}, {R: 0x60, G: 0x58, B: 0x50}},
+		alpha: curve{1, 1, 0},
+		scale: curve{1, 0.6},
+		drag:  0.99,
+	}
+	emberStyle = &particleStyle{
+		color:    gradient{{R: 0xff, G: 0xf0, B: 0xa0}, {R: 0xff, G: 0x90, B: 0x20}, {R: 0xa0, G: 0x20, B: 0x00}},
+		alpha:    curve{1, 0.8, 0},
+		scale:    curve{1, 0.3},
+		drag:     0.95,
+		additive: true,
+	}
+	trailStyle = &particleStyle{
+		color:    gradient{{R: 0xff, G: 0xe0, B: 0x80}, {R: 0xff, G: 0x70, B: 0x10}, {R: 0x60, G: 0x10, B: 0x10}},
+		alpha:    curve{0.8, 0.5, 0},
+		scale:    curve{0.6, 1, 1.4},
+		drag:     0.96,
+		additive: true,
+	}
+	sparkStyle = &particleStyle{
+		color:    gradient{{R: 0xff, G: 0xff, B: 0xff}, {R: 0x80, G: 0xd0, B: 0xff}},
+		alpha:    curve{1, 0},
+		scale:    curve{1, 0.4},
+		drag:     0.9,
+		additive: true,
+	}
+	warpStyle = &particleStyle{
+		color:    gradient{{R: 0x80, G: 0xc0, B: 0xff}, {R: 0xc0, G: 0x60, B: 0xff}},
+		alpha:    curve{0, 1, 0},
+		scale:    curve{1.2, 0.5},
+		drag:     1,
+		additive: true,
+	}
+)
+
+var (
+	debrisEmitter = &emitter{
+		style:    debrisStyle,
+		minSpeed: 0.5,
+		maxSpeed: 3,
+		spread:   math.Pi,
+		minLife:  30,
+		maxLife:  70,
+		minSize:  3,
+		maxSize:  8,
+	}
+	emberEmitter = &emitter{
+		style:    emberStyle,
+		minSpeed: 1,
+		maxSpeed: 5,
+		spread:   math.Pi,
+		minLife:  15,
+		maxLife:  35,
+		minSize:  6,
+		maxSize:  14,
+	}
+	trailEmitter = &emitter{
+		style:    trailStyle,
+		minSpeed: 2,
+		maxSpeed: 4,
+		spread:   0.25,
+		minLife:  15,
+		maxLife:  30,
+		minSize:  8,
+		maxSize:  14,
+		radius:   4,
+		rate:     3,
+	}
+	sparkEmitter = &emitter{
+		style:    sparkStyle,
+		minSpeed: 3,
+		maxSpeed: 7,
+		spread:   0.6,
+		minLife:  8,
+		maxLife:  16,
+		minSize:  3,
+		maxSize:  6,
+	}
+	// warpOutEmitter draws the ship's surroundings in to where it left,
+	// and warpInEmitter throws them out from where it arrives.
+	warpOutEmitter = &emitter{
+		style:    warpStyle,
+		minSpeed: -5,
+		maxSpeed: -3,
+		spread:   math.Pi,
+		minLife:  15,
+		maxLife:  20,
+		minSize:  6,
+		maxSize:  10,
+		radius:   80,
+		ring:     true,
+	}
+	warpInEmitter = &emitter{
+		style:    warpStyle,
+		minSpeed: 3,
+		maxSpeed: 5,
+		spread:   math.Pi,
+		minLife:  15,
+		maxLife:  20,
+		minSize:  6,
+		maxSize:  10,
+		radius:   10,
+		ring:     true,
+	}
+)
+
+// explode throws out debris carrying on with what exploded, and embers
+// around it, both more of them the bigger it was.
+func explode(s *ParticleSystem, rng *rand.Rand, e sim.Event) {
+	s.Burst(rng, debrisEmitter, int(e.Radius/2), e.Position, 0, e.Movement)
+	s.Burst(rng, emberEmitter, int(e.Radius/3), e.Position, 0, e.Movement)
+}
+
+// sparkShield sprays sparks off the shield where it was struck, away from
+// the ship.
+func sparkShield(s *ParticleSystem, rng *rand.Rand, e sim.Event, ship Vector) {
+	direction := math.Atan2(e.Position.Y-ship.Y, e.Position.X-ship.X)
+	s.Burst(rng, sparkEmitter, 6, e.Position, direction, Vector{})
+}
+
+func warp(s *ParticleSystem, rng *rand.Rand, e sim.Event) {
+	s.Burst(rng, warpOutEmitter, 40, e.Origin, 0, Vector{})
+	s.Burst(rng, warpInEmitter, 40, e.Position, 0, Vector{})
+}
+
+// exhaustDirection returns the way exhaust leaves a ship at rotation when
+// thrusting, or reversing if reverse is set, in the angles emitters take.
+func exhaustDirection(rotation float64, reverse bool) float64 {
+	if reverse {
+		return rotation - math.Pi/2
+	}
+	return rotation + math.Pi/2
+}
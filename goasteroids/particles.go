@@ -0,0 +1,240 @@
+package goasteroids
+
+import (
+	"image/color"
+	"math"
+	"math/rand/v2"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// maxParticles is the size of the particle pool. Particles emitted while it
+// is full are dropped.
+const maxParticles = 4096
+
+// particleImage is the soft dot every particle is drawn with.
+var particleImage = newParticleImage(16)
+
+func newParticleImage(size int) *ebiten.Image {
+	pixels := make([]byte, size*size*4)
+	half := float64(size) / 2
+	for y := range size {
+		for x := range size {
+			d := math.Hypot(float64(x)+0.5-half, float64(y)+0.5-half) / half
+			a := byte(255 * max(0, min(1, 1-d)) * max(0, min(1, 1-d)))
+			i := (y*size + x) * 4
+			pixels[i], pixels[i+1], pixels[i+2], pixels[i+3] = a, a, a, a
+		}
+	}
+	img := ebiten.NewImage(size, size)
+	img.WritePixels(pixels)
+	return img
+}
+
+// curve is a value over the life of a particle, with its keys spread evenly
+// from birth to death.
+type curve []float64
+
+func (c curve) at(t float64) float64 {
+	if len(c) == 1 {
+		return c[0]
+	}
+	i, f := keyAt(t, len(c))
+	return c[i] + (c[i+1]-c[i])*f
+}
+
+// keyAt returns the key of n before t, from 0 to 1, and how far t is
+// towards the next.
+func keyAt(t float64, n int) (int, float64) {
+	pos := max(0, min(t, 1)) * float64(n-1)
+	i := min(int(pos), n-2)
+	return i, pos - float64(i)
+}
+
+// gradient is a colour over the life of a particle, keyed like a curve.
+type gradient []color.RGBA
+
+func (gr gradient) at(t float64) (r, g, b float32) {
+	if len(gr) == 1 {
+		return float32(gr[0].R) / 255, float32(gr[0].G) / 255, float32(gr[0].B) / 255
+	}
+	i, f := keyAt(t, len(gr))
+	from, to := gr[i], gr[i+1]
+	mix := func(x, y uint8) float32 {
+		return float32(float64(x)+(float64(y)-float64(x))*f) / 255
+	}
+	return mix(from.R, to.R), mix(from.G, to.G), mix(from.B, to.B)
+}
+
+// particleStyle is how a particle looks and moves over its life. Its size is
+// multiplied by scale, and drag is the part of its velocity it keeps each
+// tick. Additive particles brighten what is beneath them, as light does.
+type particleStyle struct {
+	color    gradient
+	alpha    curve
+	scale    curve
+	drag     float64
+	additive bool
+}
+
+type particle struct {
+	position Vector
+	velocity Vector
+	age      int
+	lifetime int
+	size     float64
+	style    *particleStyle
+}
+
+// emitter describes how particles are sent out. They start within radius of
+// the point emitted from, or on the circle of that radius if ring is set, and
+// head off in a cone spread radians either side of the direction given. A
+// spread of math.Pi sends them all around. A negative speed sends them
+// backwards, into a ring for example. Continuous emitters send rate particles
+// a tick.
+type emitter struct {
+	style              *particleStyle
+	minSpeed, maxSpeed float64
+	spread             float64
+	minLife, maxLife   int
+	minSize, maxSize   float64
+	radius             float64
+	ring               bool
+	rate               float64
+}
+
+// stream keeps a continuous emitter going from tick to tick, carrying over
+// the part of a particle a tick at a fractional rate does not emit.
+type stream struct {
+	emitter *emitter
+	carry   float64
+}
+
+// ParticleSystem keeps a fixed pool of particles, the live ones at the front,
+// and draws them all in a single batch for each blend.
+type ParticleSystem struct {
+	particles []particle
+	live      int
+	vertices  []ebiten.Vertex
+	indices   []uint16
+}
+
+func NewParticleSystem() *ParticleSystem {
+	return &ParticleSystem{particles: make([]particle, maxParticles)}
+}
+
+// Burst emits count particles at once from pos towards direction, in
+// radians with 0 to the right. Each also carries on with inherit, the
+// movement of what emitted it.
+func (s *ParticleSystem) Burst(rng *rand.Rand, e *emitter, count int, pos Vector, direction float64, inherit Vector) {
+	for range count {
+		if s.live == len(s.particles) {
+			return
+		}
+
+		angle := direction + (rng.Float64()*2-1)*e.spread
+		dirX, dirY := math.Cos(angle), math.Sin(angle)
+		speed := e.minSpeed + rng.Float64()*(e.maxSpeed-e.minSpeed)
+
+		start := pos
+		if e.radius > 0 {
+			r := e.radius
+			offset := angle
+			if !e.ring {
+				r *= math.Sqrt(rng.Float64())
+				offset = rng.Float64() * 2 * math.Pi
+			}
+			start.X += math.Cos(offset) * r
+			start.Y += math.Sin(offset) * r
+		}
+
+		s.particles[s.live] = particle{
+			position: start,
+			velocity: Vector{X: dirX*speed + inherit.X, Y: dirY*speed + inherit.Y},
+			lifetime: e.minLife + rng.IntN(e.maxLife-e.minLife+1),
+			size:     e.minSize + rng.Float64()*(e.maxSize-e.minSize),
+			style:    e.style,
+		}
+		s.live++
+	}
+}
+
+// emit sends out this tick's share of the stream's particles.
+func (st *stream) emit(s *ParticleSystem, rng *rand.Rand, pos Vector, direction float64, inherit Vector) {
+	st.carry += st.emitter.rate
+	count := int(st.carry)
+	st.carry -= float64(count)
+	s.Burst(rng, st.emitter, count, pos, direction, inherit)
+}
+
+// Update moves every particle on by a tick and returns those that have
+// lived out their lifetime to the pool.
+func (s *ParticleSystem) Update() {
+	for i := 0; i < s.live; {
+		p := &s.particles[i]
+		p.age++
+		if p.age >= p.lifetime {
+			s.live--
+			s.particles[i] = s.particles[s.live]
+			continue
+		}
+
+		p.position.X += p.velocity.X
+		p.position.Y += p.velocity.Y
+		p.velocity.X *= p.style.drag
+		p.velocity.Y *= p.style.drag
+		i++
+	}
+}
+
+// Clear removes every particle.
+func (s *ParticleSystem) Clear() {
+	s.live = 0
+}
+
+func (s *ParticleSystem) Draw(screen *ebiten.Image) {
+	s.drawBatch(screen, false)
+	s.drawBatch(screen, true)
+}
+
+func (s *ParticleSystem) drawBatch(screen *ebiten.Image, additive bool) {
+	s.vertices = s.vertices[:0]
+	s.indices = s.indices[:0]
+	srcSize := float32(particleImage.Bounds().Dx())
+
+	for _, p := range s.particles[:s.live] {
+		if p.style.additive != additive {
+			continue
+		}
+
+		t := float64(p.age) / float64(p.lifetime)
+		half := float32(p.size * p.style.scale.at(t) / 2)
+		r, g, b := p.style.color.at(t)
+		a := float32(p.style.alpha.at(t))
+		x, y := float32(p.position.X), float32(p.position.Y)
+
+		n := uint16(len(s.vertices))
+		for _, corner := range [4][2]float32{{-1, -1}, {1, -1}, {-1, 1}, {1, 1}} {
+			s.vertices = append(s.vertices, ebiten.Vertex{
+				DstX:   x + corner[0]*half,
+				DstY:   y + corner[1]*half,
+				SrcX:   (corner[0] + 1) / 2 * srcSize,
+				SrcY:   (corner[1] + 1) / 2 * srcSize,
+				ColorR: r,
+				ColorG: g,
+				ColorB: b,
+				ColorA: a,
+			})
+		}
+		s.indices = append(s.indices, n, n+1, n+2, n+1, n+3, n+2)
+	}
+
+	if len(s.indices) == 0 {
+		return
+	}
+	op := &ebiten.DrawTrianglesOptions{}
+	if additive {
+		op.Blend = ebiten.BlendLighter
+	}
+	screen.DrawTriangles(s.vertices, s.indices, particleImage, op)
+}
@@ -0,0 +1,96 @@
+package goasteroids
+
+import (
+	"go-asteroids/assets"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	pauseRowResume = iota
+	pauseRowRestart
+	pauseRowOptions
+	pauseRowQuit
+	pauseRowCount
+)
+
+var pauseRowLabels = [pauseRowCount]string{
+	pauseRowResume:  "RESUME",
+	pauseRowRestart: "RESTART",
+	pauseRowOptions: "OPTIONS",
+	pauseRowQuit:    "QUIT TO TITLE",
+}
+
+var pauseDimColor = color.RGBA{A: 0xb0}
+
+// PauseScene is pushed over a game in progress. The game is not updated while
+// it is covered, so its world, timers and sounds stay exactly where they were.
+type PauseScene struct {
+	game     *GameScene
+	selected int
+}
+
+func NewPauseScene(game *GameScene) *PauseScene {
+	return &PauseScene{game: game}
+}
+
+func (p *PauseScene) overlay() {}
+
+func (p *PauseScene) Update(state *State) error {
+	if state.Input.IsJustPressed(ActionPause) || state.Input.IsJustPressed(ActionBack) {
+		state.SceneManager.Pop(Instant{})
+		return nil
+	}
+
+	if state.Input.IsJustPressed(ActionUp) {
+		p.selected = (p.selected + pauseRowCount - 1) % pauseRowCount
+	}
+
+	if state.Input.IsJustPressed(ActionDown) {
+		p.selected = (p.selected + 1) % pauseRowCount
+	}
+
+	if !state.Input.IsJustPressed(ActionConfirm) {
+		return nil
+	}
+
+	switch p.selected {
+	case pauseRowResume:
+		state.SceneManager.Pop(Instant{})
+	case pauseRowRestart:
+		p.game.Reset(state.Options.gameSeed())
+		state.Camera.Settle()
+		state.SceneManager.Pop(Iris{Length: defaultTransitionTicks})
+	case pauseRowOptions:
+		state.SceneManager.Push(NewOptionsScene(state.Settings))
+	case pauseRowQuit:
+		p.game.quitToTitle(state)
+	}
+
+	return nil
+}
+
+// Draw draws nothing on the playfield, which is left showing the game.
+func (p *PauseScene) Draw(*ebiten.Image) {}
+
+// DrawHUD dims the whole view, game and HUD alike, and draws the menu over
+// it.
+func (p *PauseScene) DrawHUD(screen *ebiten.Image, view viewport) {
+	vector.DrawFilledRect(screen, 0, 0, float32(view.width), float32(view.height), pauseDimColor, false)
+
+	x, y := view.width/2, view.height/2-120
+	drawText(screen, "PAUSED", assets.TitleFont, 48, x, y, text.AlignCenter, color.White)
+
+	y += 100
+	for row, label := range pauseRowLabels {
+		clr := color.Color(color.White)
+		if row == p.selected {
+			clr = selectedColor
+		}
+		drawText(screen, label, assets.ScoreFont, 16, x, y, text.AlignCenter, clr)
+		y += 28
+	}
+}
@@ -0,0 +1,145 @@
+package goasteroids
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// Every save file starts with a header line holding a checksum of the rest of
+// the file, so that a torn or truncated write is detected on load rather than
+// parsed as garbage:
+//
+//	GASAVE 1 <crc32 in hex> <payload length>\n
+//
+// The payload follows unchanged, which keeps the JSON files readable.
+const (
+	saveMagic   = "GASAVE"
+	saveVersion = 1
+	backupExt   = ".bak"
+)
+
+var errChecksum = errors.New("checksum mismatch")
+
+// writeSaveFile replaces the file at path with data without ever leaving a
+// partly written file behind. The data goes to a temporary file that is
+// synced and then renamed over path, and the previous contents are kept as a
+// single backup generation next to it.
+func writeSaveFile(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return fmt.Errorf("failed to create directory %s: %w", dir, err)
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	header := fmt.Sprintf("%s %d %08x %d\n", saveMagic, saveVersion, crc32.ChecksumIEEE(data), len(data))
+	if _, err := tmp.WriteString(header); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	// Only a file that still verifies is worth keeping as the backup, so a
+	// corrupt save never replaces a good backup.
+	if _, err := readSaveFile(path); err == nil {
+		if err := os.Rename(path, path+backupExt); err != nil {
+			return fmt.Errorf("failed to back up %s: %w", path, err)
+		}
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	syncDir(dir)
+	return nil
+}
+
+// loadSaveFile returns the verified contents of the file at path. If the file
+// is missing or fails its checksum, the backup is used instead. The error
+// wraps os.ErrNotExist when neither exists.
+func loadSaveFile(path string) ([]byte, error) {
+	data, err := readSaveFile(path)
+	if err == nil {
+		return data, nil
+	}
+
+	backup, backupErr := readSaveFile(path + backupExt)
+	if backupErr != nil {
+		if errors.Is(err, os.ErrNotExist) && !errors.Is(backupErr, os.ErrNotExist) {
+			return nil, backupErr
+		}
+		return nil, err
+	}
+
+	log.Printf("Recovered %s from its backup: %v", filepath.Base(path), err)
+	return backup, nil
+}
+
+// readSaveFile reads and verifies a single file. A file without a complete
+// header, such as an empty one left by a crash, fails as a checksum mismatch
+// so that the backup is used. The only file from before checksums were added,
+// the old highscore.txt, is read directly when it is migrated.
+func readSaveFile(path string) ([]byte, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.HasPrefix(contents, []byte(saveMagic+" ")) {
+		return nil, fmt.Errorf("%s: missing header: %w", filepath.Base(path), errChecksum)
+	}
+
+	header, data, ok := bytes.Cut(contents, []byte("\n"))
+	if !ok {
+		return nil, fmt.Errorf("%s: truncated header: %w", filepath.Base(path), errChecksum)
+	}
+
+	var magic string
+	var version, length int
+	var sum uint32
+	if _, err := fmt.Sscanf(string(header), "%s %d %x %d", &magic, &version, &sum, &length); err != nil {
+		return nil, fmt.Errorf("%s: invalid header: %w", filepath.Base(path), err)
+	}
+	if version != saveVersion {
+		return nil, fmt.Errorf("%s: unsupported save version %d", filepath.Base(path), version)
+	}
+	if len(data) != length || crc32.ChecksumIEEE(data) != sum {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(path), errChecksum)
+	}
+	return data, nil
+}
+
+// syncDir flushes a directory so that a rename in it survives a power loss.
+// Windows does not support syncing directories and orders renames itself.
+func syncDir(dir string) {
+	if runtime.GOOS == "windows" {
+		return
+	}
+
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	_ = d.Sync()
+	_ = d.Close()
+}
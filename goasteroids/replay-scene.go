@@ -0,0 +1,95 @@
+package goasteroids
+
+import (
+	"fmt"
+	"go-asteroids/assets"
+	"go-asteroids/sim"
+	"image/color"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+)
+
+var replaySpeeds = []int{1, 2, 4, 8}
+
+// ReplayScene plays a recorded game back. Confirm pauses, right speeds the
+// playback up or, while paused, steps a single tick, and left slows it down.
+//
+// It is pushed over the scene it was started from, which is returned to when
+// the player leaves.
+type ReplayScene struct {
+	game   *GameScene
+	speed  int
+	paused bool
+}
+
+func NewReplayScene(r *sim.Replay, audio *AudioManager) *ReplayScene {
+	return &ReplayScene{
+		game: NewPlaybackScene(r, audio),
+	}
+}
+
+func (r *ReplayScene) music() MusicTrack { return MusicGameplay }
+
+func (r *ReplayScene) OnExit() { r.game.pauseSounds() }
+
+func (r *ReplayScene) OnShutdown() error { return r.game.OnShutdown() }
+
+func (r *ReplayScene) Update(state *State) error {
+	if state.Input.IsJustPressed(ActionBack) {
+		state.SceneManager.Pop()
+		return nil
+	}
+
+	if state.Input.IsJustPressed(ActionConfirm) {
+		r.paused = !r.paused
+		if r.paused {
+			r.game.pauseSounds()
+		}
+	}
+
+	if r.paused {
+		if state.Input.IsJustPressed(ActionRight) {
+			return r.game.Update(state)
+		}
+		return nil
+	}
+
+	if state.Input.IsJustPressed(ActionRight) {
+		r.speed = min(r.speed+1, len(replaySpeeds)-1)
+	}
+
+	if state.Input.IsJustPressed(ActionLeft) {
+		r.speed = max(r.speed-1, 0)
+	}
+
+	for range replaySpeeds[r.speed] {
+		if err := r.game.Update(state); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *ReplayScene) Draw(screen *ebiten.Image) {
+	r.game.Draw(screen)
+}
+
+func (r *ReplayScene) DrawHUD(screen *ebiten.Image, view viewport) {
+	r.game.DrawHUD(screen, view)
+
+	x, y := view.anchor(anchorTopRight, 20, 20)
+	drawText(screen, "REPLAY", assets.ScoreFont, 16, x, y, text.AlignEnd, selectedColor)
+
+	status := fmt.Sprintf("%dX", replaySpeeds[r.speed])
+	switch {
+	case r.game.PlaybackDone():
+		status = "END OF REPLAY"
+	case r.paused:
+		status = "PAUSED"
+	}
+	drawText(screen, status, assets.ScoreFont, 16, x, y+25, text.AlignEnd, color.White)
+
+	ticks := fmt.Sprintf("%d / %d", r.game.playbackTick, len(r.game.playback.Frames))
+	drawText(screen, ticks, assets.ScoreFont, 12, x, y+50, text.AlignEnd, color.Gray{Y: 0x80})
+}
@@ -0,0 +1,105 @@
+package goasteroids
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go-asteroids/sim"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+const (
+	replayExtension = ".replay"
+	// maxReplays is how many of the most recent games are kept on disk.
+	maxReplays = 20
+)
+
+func getReplayDir() (string, error) {
+	dir, err := getAppDataDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get app data directory: %w", err)
+	}
+
+	dir = filepath.Join(dir, "replays")
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return "", fmt.Errorf("failed to create directory %s: %w", dir, err)
+	}
+	return dir, nil
+}
+
+// saveReplay writes r to a new file in the replay directory, named after the
+// time it was saved so that the files sort chronologically. The time is kept
+// to the nanosecond, so that games saved in the same second with the same
+// seed never share a file.
+func saveReplay(r *sim.Replay) (string, error) {
+	dir, err := getReplayDir()
+	if err != nil {
+		return "", err
+	}
+
+	name := fmt.Sprintf("%s-%d%s", time.Now().Format("20060102-150405.000000000"), r.Seed, replayExtension)
+	path := filepath.Join(dir, name)
+
+	var buf bytes.Buffer
+	if _, err := r.WriteTo(&buf); err != nil {
+		return "", fmt.Errorf("failed to encode replay: %w", err)
+	}
+
+	if err := writeSaveFile(path, buf.Bytes()); err != nil {
+		return "", fmt.Errorf("failed to write replay file: %w", err)
+	}
+
+	pruneReplays(dir)
+	return path, nil
+}
+
+// pruneReplays removes all but the newest maxReplays files from dir, along
+// with their backups.
+func pruneReplays(dir string) {
+	matches, err := filepath.Glob(filepath.Join(dir, "*"+replayExtension))
+	if err != nil || len(matches) <= maxReplays {
+		return
+	}
+
+	slices.Sort(matches)
+	for _, path := range matches[:len(matches)-maxReplays] {
+		if err := os.Remove(path); err != nil {
+			log.Println("Error removing old replay:", err)
+		}
+		if err := os.Remove(path + backupExt); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Println("Error removing old replay backup:", err)
+		}
+	}
+}
+
+func loadReplay(path string) (*sim.Replay, error) {
+	contents, err := loadSaveFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read replay file: %w", err)
+	}
+
+	r, err := sim.ReadReplay(bytes.NewReader(contents))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read replay %s: %w", path, err)
+	}
+	return r, nil
+}
+
+// latestReplay returns the path of the most recently saved replay, or an
+// empty string if there is none.
+func latestReplay() (string, error) {
+	dir, err := getReplayDir()
+	if err != nil {
+		return "", err
+	}
+
+	matches, err := filepath.Glob(filepath.Join(dir, "*"+replayExtension))
+	if err != nil || len(matches) == 0 {
+		return "", err
+	}
+	return slices.Max(matches), nil
+}
@@ -0,0 +1,87 @@
+package goasteroids
+
+import (
+	"go-asteroids/sim"
+	"os"
+	"time"
+)
+
+// rulesPollInterval is how often the watched files are checked for changes.
+const rulesPollInterval = time.Second
+
+// rulesWatcher notices when the tuning or levels file is saved. It polls the
+// modification time and size of each file, which is cheap enough to do every
+// second and needs no platform specific file notifications.
+type rulesWatcher struct {
+	tuningFile string
+	levelsFile string
+	pollTimer  *Timer
+	stats      map[string]fileStat
+}
+
+type fileStat struct {
+	modTime time.Time
+	size    int64
+}
+
+func newRulesWatcher(tuningFile, levelsFile string) *rulesWatcher {
+	w := &rulesWatcher{
+		tuningFile: tuningFile,
+		levelsFile: levelsFile,
+		pollTimer:  NewTimer(rulesPollInterval),
+		stats:      make(map[string]fileStat),
+	}
+	w.changed()
+	return w
+}
+
+// Update reports whether either file has changed since the last poll.
+func (w *rulesWatcher) Update() bool {
+	w.pollTimer.Update()
+	if !w.pollTimer.IsReady() {
+		return false
+	}
+	w.pollTimer.Reset()
+	return w.changed()
+}
+
+func (w *rulesWatcher) changed() bool {
+	changed := false
+	for _, path := range []string{w.tuningFile, w.levelsFile} {
+		if path == "" {
+			continue
+		}
+
+		var stat fileStat
+		if info, err := os.Stat(path); err == nil {
+			stat = fileStat{modTime: info.ModTime(), size: info.Size()}
+		}
+		if stat != w.stats[path] {
+			w.stats[path] = stat
+			changed = true
+		}
+	}
+	return changed
+}
+
+// load reads both files into a copy of rules. Anything not loaded from a
+// file is kept as it is.
+func (w *rulesWatcher) load(rules sim.Rules) (sim.Rules, error) {
+	if w.tuningFile != "" {
+		tuning, err := sim.LoadTuning(w.tuningFile)
+		if err != nil {
+			return rules, err
+		}
+		rules.Tuning = tuning
+	}
+
+	if w.levelsFile != "" {
+		levels, err := sim.LoadLevels(w.levelsFile)
+		if err != nil {
+			return rules, err
+		}
+		rules.Levels = levels
+	}
+
+	return rules, nil
+}
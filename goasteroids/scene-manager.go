@@ -1,72 +1,302 @@
 package goasteroids
 
-import "github.com/hajimehoshi/ebiten/v2"
+import (
+	"errors"
+	"go-asteroids/sim"
+	"slices"
 
-var (
-	transitionFrom = ebiten.NewImage(ScreenWidth, ScreenHeight)
-	transitionTo   = ebiten.NewImage(ScreenWidth, ScreenHeight)
+	"github.com/hajimehoshi/ebiten/v2"
 )
 
-const transitionMaxCount = 25
+// playfieldImage is what scenes draw the playfield on before it is placed in
+// the view.
+var playfieldImage = ebiten.NewImage(ScreenWidth, ScreenHeight)
 
 type Scene interface {
 	Update(state *State) error
 	Draw(screen *ebiten.Image)
 }
 
+// hudDrawer is implemented by scenes with a heads-up display. It is drawn on
+// the whole view after the playfield, so that it can sit against the edges
+// of the window however wide the view is.
+type hudDrawer interface {
+	DrawHUD(screen *ebiten.Image, view viewport)
+}
+
+// overlay is implemented by scenes drawn over the scene beneath them on the
+// stack rather than instead of it.
+type overlay interface {
+	overlay()
+}
+
+// Scenes may implement any of the lifecycle hooks below. A scene is entered
+// or resumed when it starts being updated at the top of the stack, which is
+// once any transition to it has finished. It is exited or suspended as soon
+// as it stops being updated, when it is replaced or covered.
+type (
+	sceneEnterer   interface{ OnEnter() }
+	sceneExiter    interface{ OnExit() }
+	sceneSuspender interface{ OnSuspend() }
+	sceneResumer   interface{ OnResume() }
+)
+
+// sceneShutdowner is implemented by scenes with something to save or release
+// when the game exits.
+type sceneShutdowner interface {
+	OnShutdown() error
+}
+
+// musicScene is implemented by scenes with music of their own. The music of
+// the highest such scene on the stack plays, crossfading in step with the
+// transition to it.
+type musicScene interface {
+	music() MusicTrack
+}
+
+// rulesApplier is implemented by scenes holding a game that should pick up
+// rules reloaded while it is running.
+type rulesApplier interface {
+	applyRules(rules sim.Rules)
+}
+
 type State struct {
 	SceneManager *SceneManager
 	Input        *Input
+	Options      *Options
+	Settings     *Settings
+	Session      *Session
+	Audio        *AudioManager
+	Camera       *Camera
 }
 
+// SceneManager keeps a stack of scenes. Only the top scene is updated, while
+// overlays let the scenes beneath them show through.
 type SceneManager struct {
-	current         Scene
-	next            Scene
-	transitionCount int
+	options  *Options
+	settings *Settings
+	session  *Session
+	audio    *AudioManager
+	camera   Camera
+	stack    []Scene
+	// next is the stack being changed to while transition runs, and arrive
+	// calls the hooks of the scene that will be on top when it is done.
+	next           []Scene
+	transition     Transition
+	transitionTick int
+	arrive         func()
+	transitionFrom *ebiten.Image
+	transitionTo   *ebiten.Image
+	hudLayer       *ebiten.Image
 }
 
-func (s *SceneManager) Draw(r *ebiten.Image) {
-	if s.transitionCount == 0 {
-		s.current.Draw(r)
+// Draw draws the scenes onto r, which is the size of the view.
+func (s *SceneManager) Draw(r *ebiten.Image, view viewport) {
+	if s.transition == nil {
+		s.drawStack(r, s.stack, view)
 		return
 	}
 
-	transitionFrom.Clear()
-	s.current.Draw(transitionFrom)
+	s.transitionFrom = fitImage(s.transitionFrom, view)
+	s.transitionFrom.Clear()
+	s.drawStack(s.transitionFrom, s.stack, view)
 
-	transitionTo.Clear()
-	s.next.Draw(transitionTo)
+	s.transitionTo = fitImage(s.transitionTo, view)
+	s.transitionTo.Clear()
+	s.drawStack(s.transitionTo, s.next, view)
 
-	r.DrawImage(transitionFrom, nil)
+	progress := float32(s.transitionTick) / float32(s.transition.Ticks())
+	s.transition.Draw(r, s.transitionFrom, s.transitionTo, progress)
+}
+
+// drawStack draws the top scene of stack along with any scenes it and the
+// overlays beneath it are drawn over.
+func (s *SceneManager) drawStack(r *ebiten.Image, stack []Scene, view viewport) {
+	bottom := len(stack) - 1
+	for bottom > 0 {
+		if _, ok := stack[bottom].(overlay); !ok {
+			break
+		}
+		bottom--
+	}
+
+	for i := bottom; i < len(stack); i++ {
+		s.drawScene(r, stack[i], view, i == bottom)
+	}
+}
+
+// drawScene draws the playfield of scene through the camera and then its HUD.
+// The HUD fades with the HUD opacity setting, except for overlays, whose HUD
+// is their menu.
+func (s *SceneManager) drawScene(r *ebiten.Image, scene Scene, view viewport, bottom bool) {
+	playfieldImage.Clear()
+	scene.Draw(playfieldImage)
+	view.drawPlayfield(r, s.camera.apply(playfieldImage), bottom)
+
+	h, ok := scene.(hudDrawer)
+	if !ok {
+		return
+	}
+	if _, ok := scene.(overlay); ok || view.hudOpacity >= 1 {
+		h.DrawHUD(r, view)
+		return
+	}
+
+	s.hudLayer = fitImage(s.hudLayer, view)
+	s.hudLayer.Clear()
+	h.DrawHUD(s.hudLayer, view)
 
-	alpha := 1 - float32(s.transitionCount)/float32(transitionMaxCount)
 	op := &ebiten.DrawImageOptions{}
-	op.ColorScale.ScaleAlpha(alpha)
-	r.DrawImage(transitionTo, op)
+	op.ColorScale.ScaleAlpha(view.hudOpacity)
+	r.DrawImage(s.hudLayer, op)
 }
 
-func (s *SceneManager) Update(_ *Input) error {
-	if s.transitionCount == 0 {
-		return s.current.Update(&State{
+func (s *SceneManager) Update(input *Input) error {
+	s.camera.Update(s.settings.ShakeIntensity)
+
+	if s.transition == nil {
+		return s.stack[len(s.stack)-1].Update(&State{
 			SceneManager: s,
+			Input:        input,
+			Options:      s.options,
+			Settings:     s.settings,
+			Session:      s.session,
+			Audio:        s.audio,
+			Camera:       &s.camera,
 		})
 	}
 
-	s.transitionCount--
-	if s.transitionCount > 0 {
+	s.transitionTick++
+	if s.transitionTick < s.transition.Ticks() {
 		return nil
 	}
 
-	s.current = s.next
-	s.next = nil
+	s.finishTransition()
 	return nil
 }
 
-func (s *SceneManager) GoToScene(scene Scene) {
-	if s.current == nil {
-		s.current = scene
-	} else {
-		s.next = scene
-		s.transitionCount = transitionMaxCount
+// GoToScene replaces every scene on the stack with scene. The change uses the
+// first transition given, or a crossfade if there is none.
+func (s *SceneManager) GoToScene(scene Scene, transition ...Transition) {
+	for i := len(s.stack) - 1; i >= 0; i-- {
+		if e, ok := s.stack[i].(sceneExiter); ok {
+			e.OnExit()
+		}
+	}
+
+	s.change([]Scene{scene}, func() {
+		if e, ok := scene.(sceneEnterer); ok {
+			e.OnEnter()
+		}
+	}, transition)
+}
+
+// Push puts scene on top of the stack, keeping the scenes beneath it to
+// return to with Pop.
+func (s *SceneManager) Push(scene Scene, transition ...Transition) {
+	if len(s.stack) > 0 {
+		if p, ok := s.stack[len(s.stack)-1].(sceneSuspender); ok {
+			p.OnSuspend()
+		}
+	}
+
+	s.change(append(slices.Clone(s.stack), scene), func() {
+		if e, ok := scene.(sceneEnterer); ok {
+			e.OnEnter()
+		}
+	}, transition)
+}
+
+// Pop removes the top scene and returns to the one beneath it. The last
+// scene is never popped.
+func (s *SceneManager) Pop(transition ...Transition) {
+	if len(s.stack) < 2 {
+		return
+	}
+
+	top := s.stack[len(s.stack)-1]
+	if e, ok := top.(sceneExiter); ok {
+		e.OnExit()
+	}
+
+	next := slices.Clone(s.stack[:len(s.stack)-1])
+	s.change(next, func() {
+		if r, ok := next[len(next)-1].(sceneResumer); ok {
+			r.OnResume()
+		}
+	}, transition)
+}
+
+func (s *SceneManager) change(next []Scene, arrive func(), transition []Transition) {
+	t := defaultTransition
+	if len(transition) > 0 {
+		t = transition[0]
+	}
+
+	s.next = next
+	s.arrive = arrive
+	s.transition = t
+	s.transitionTick = 0
+	s.changeMusic(next, t.Ticks())
+
+	// The first scene has nothing to transition from.
+	if len(s.stack) == 0 || t.Ticks() <= 0 {
+		s.finishTransition()
+	}
+}
+
+// changeMusic fades to the music of the scenes being changed to over ticks.
+// Scenes without music of their own keep whatever is playing.
+func (s *SceneManager) changeMusic(next []Scene, ticks int) {
+	if s.audio == nil {
+		return
+	}
+	for i := len(next) - 1; i >= 0; i-- {
+		if m, ok := next[i].(musicScene); ok {
+			s.audio.PlayMusic(m.music(), ticks)
+			return
+		}
+	}
+}
+
+func (s *SceneManager) finishTransition() {
+	s.stack = s.next
+	s.next = nil
+	s.transition = nil
+
+	arrive := s.arrive
+	s.arrive = nil
+	arrive()
+}
+
+// applyRules hands reloaded rules to every scene on the stack and to those
+// being transitioned to.
+func (s *SceneManager) applyRules(rules sim.Rules) {
+	for _, scene := range slices.Concat(s.stack, s.next) {
+		if a, ok := scene.(rulesApplier); ok {
+			a.applyRules(rules)
+		}
+	}
+}
+
+// shutdown lets every scene on the stack, and any being transitioned to,
+// save or release what it holds.
+func (s *SceneManager) shutdown() error {
+	var errs []error
+	var done []Scene
+	scenes := slices.Concat(s.next, s.stack)
+	for i := len(scenes) - 1; i >= 0; i-- {
+		scene := scenes[i]
+		if slices.Contains(done, scene) {
+			continue
+		}
+		done = append(done, scene)
+
+		if sd, ok := scene.(sceneShutdowner); ok {
+			if err := sd.OnShutdown(); err != nil {
+				errs = append(errs, err)
+			}
+		}
 	}
+	return errors.Join(errs...)
 }
@@ -0,0 +1,112 @@
+package goasteroids
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+const settingsVersion = 1
+
+// Settings are the player's preferences from the options menu. Unlike
+// Options they are saved between sessions.
+type Settings struct {
+	// Ghost draws the ship from the best run so far alongside the player.
+	Ghost bool `json:"ghost"`
+	// MasterVolume scales every sound, on top of SFXVolume for the sound
+	// effects and MusicVolume for the music and heartbeat.
+	MasterVolume float64 `json:"master_volume"`
+	SFXVolume    float64 `json:"sfx_volume"`
+	MusicVolume  float64 `json:"music_volume"`
+	Fullscreen   bool    `json:"fullscreen"`
+	Vsync        bool    `json:"vsync"`
+	// Aspect and Scaling decide how the playfield fills the window.
+	Aspect  AspectMode `json:"aspect"`
+	Scaling ScaleMode  `json:"scaling"`
+	// ShakeIntensity scales how much the camera shakes, holds and zooms on
+	// impacts, with zero turning it off.
+	ShakeIntensity float64 `json:"shake_intensity"`
+	// StarDensity is the share of the stars drawn in every starfield.
+	StarDensity float64 `json:"star_density"`
+	// HUDOpacity is how opaque the lives, scores and other readouts are.
+	HUDOpacity float64 `json:"hud_opacity"`
+}
+
+func DefaultSettings() Settings {
+	return Settings{
+		Ghost:          true,
+		MasterVolume:   1,
+		SFXVolume:      1,
+		MusicVolume:    1,
+		Fullscreen:     true,
+		Vsync:          true,
+		Aspect:         AspectLetterbox,
+		Scaling:        ScaleSmooth,
+		ShakeIntensity: 1,
+		StarDensity:    1,
+		HUDOpacity:     1,
+	}
+}
+
+// clamp brings every level setting into the range 0 to 1, in case the file
+// was edited by hand.
+func (s *Settings) clamp() {
+	for _, v := range []*float64{&s.MasterVolume, &s.SFXVolume, &s.MusicVolume, &s.ShakeIntensity, &s.StarDensity, &s.HUDOpacity} {
+		*v = max(0, min(*v, 1))
+	}
+}
+
+type settingsFile struct {
+	Version int `json:"version"`
+	Settings
+}
+
+func getSettingsFile() (string, error) {
+	return getDataFile("settings.json")
+}
+
+// loadSettings reads the saved settings. Fields missing from the file keep
+// their defaults.
+func loadSettings() (Settings, error) {
+	f := settingsFile{Version: settingsVersion, Settings: DefaultSettings()}
+
+	path, err := getSettingsFile()
+	if err != nil {
+		return f.Settings, err
+	}
+
+	contents, err := loadSaveFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return f.Settings, nil
+	}
+	if err != nil {
+		return f.Settings, fmt.Errorf("failed to read settings file: %w", err)
+	}
+
+	if err := json.Unmarshal(contents, &f); err != nil {
+		return DefaultSettings(), fmt.Errorf("failed to parse settings file: %w", err)
+	}
+	if f.Version != settingsVersion {
+		return DefaultSettings(), fmt.Errorf("unsupported settings file version %d", f.Version)
+	}
+	f.Settings.clamp()
+	return f.Settings, nil
+}
+
+func saveSettings(s Settings) error {
+	path, err := getSettingsFile()
+	if err != nil {
+		return err
+	}
+
+	contents, err := json.MarshalIndent(settingsFile{Version: settingsVersion, Settings: s}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode settings: %w", err)
+	}
+
+	if err := writeSaveFile(path, contents); err != nil {
+		return fmt.Errorf("failed to write settings file: %w", err)
+	}
+	return nil
+}
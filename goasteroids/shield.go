@@ -2,53 +2,32 @@ package goasteroids
 
 import (
 	"go-asteroids/assets"
+	"go-asteroids/sim"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/solarlune/resolv"
 )
 
 type Shield struct {
-	position  Vector
-	rotation  float64
-	sprite    *ebiten.Image
-	shiledObj *resolv.Circle
-	game      *GameScene
+	position Vector
+	rotation float64
+	sprite   *ebiten.Image
 }
 
-func NewShield(game *GameScene, position Vector, rotation float64) *Shield {
-	sprite := assets.ShieldSprite
-	halfW, halfH := HalfOfTheImage(sprite)
-
-	position.X -= halfW
-	position.Y -= halfH
-
-	shieldObj := resolv.NewCircle(0, 0, halfW)
-
-	s := &Shield{
-		position:  position,
-		rotation:  rotation,
-		sprite:    sprite,
-		shiledObj: shieldObj,
-		game:      game,
+func NewShield() *Shield {
+	return &Shield{
+		sprite: assets.ShieldSprite,
 	}
-
-	s.game.space.Add(s.shiledObj)
-
-	return s
 }
 
-func (s *Shield) Update() {
-	diffX := float64(s.sprite.Bounds().Dx()-s.game.player.sprite.Bounds().Dx()) * 0.5
-	diffY := float64(s.sprite.Bounds().Dy()-s.game.player.sprite.Bounds().Dy()) * 0.5
+func (s *Shield) Update(p *sim.Player) {
+	diffX := (float64(s.sprite.Bounds().Dx()) - sim.PlayerSize.W) * 0.5
+	diffY := (float64(s.sprite.Bounds().Dy()) - sim.PlayerSize.H) * 0.5
 
-	pos := Vector{
-		X: s.game.player.position.X - diffX,
-		Y: s.game.player.position.Y - diffY,
+	s.position = Vector{
+		X: p.Position.X - diffX,
+		Y: p.Position.Y - diffY,
 	}
-
-	s.position = pos
-	s.rotation = s.game.player.rotation
-	s.shiledObj.Move(pos.X, pos.Y)
+	s.rotation = p.Rotation
 }
 
 func (s *Shield) Draw(screen *ebiten.Image) {
@@ -59,8 +38,8 @@ func (s *Shield) Draw(screen *ebiten.Image) {
 	op.GeoM.Translate(-halfW, -halfH)
 	op.GeoM.Rotate(s.rotation)
 	op.GeoM.Translate(halfW, halfH)
-	
+
 	op.GeoM.Translate(s.position.X, s.position.Y)
-	
+
 	screen.DrawImage(s.sprite, op)
 }
@@ -0,0 +1,70 @@
+package goasteroids
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Session is a summary of one run of the game, appended to the session log
+// when it exits.
+type Session struct {
+	Started   time.Time
+	Games     int
+	BestScore int
+}
+
+// recordGame counts a finished game towards the session.
+func (s *Session) recordGame(score int) {
+	s.Games++
+	s.BestScore = max(s.BestScore, score)
+}
+
+func (s *Session) String() string {
+	return fmt.Sprintf("%s ran %s, played %d games, best score %d",
+		s.Started.Format(time.RFC3339), time.Since(s.Started).Round(time.Second), s.Games, s.BestScore)
+}
+
+// writeSessionLog appends the session to the log in the data directory.
+func writeSessionLog(s *Session) error {
+	path, err := getDataFile("sessions.log")
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0640)
+	if err != nil {
+		return fmt.Errorf("failed to open session log: %w", err)
+	}
+	if _, err := fmt.Fprintln(f, s); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write session log: %w", err)
+	}
+	return f.Close()
+}
+
+// shutdownHook is a step of shutting the game down. Every hook runs even if
+// an earlier one fails.
+type shutdownHook struct {
+	name string
+	run  func() error
+}
+
+func (g *Game) addShutdownHook(name string, run func() error) {
+	g.shutdownHooks = append(g.shutdownHooks, shutdownHook{name: name, run: run})
+}
+
+// shutdown runs the shutdown hooks in the order they were added and returns
+// ebiten.Termination to end the game loop.
+func (g *Game) shutdown() error {
+	for _, h := range g.shutdownHooks {
+		if err := h.run(); err != nil {
+			log.Printf("Error shutting down %s: %v", h.name, err)
+		}
+	}
+	g.shutdownHooks = nil
+	return ebiten.Termination
+}
@@ -2,7 +2,7 @@ package goasteroids
 
 import (
 	"image/color"
-	"math/rand"
+	"math/rand/v2"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/vector"
@@ -15,12 +15,16 @@ type Star struct {
 	brightness float32
 }
 
-func NewStar() *Star {
+func NewStar(rng *rand.Rand) *Star {
+	return newStarIn(rng, ScreenWidth, ScreenHeight)
+}
+
+func newStarIn(rng *rand.Rand, width, height float32) *Star {
 	return &Star{
-		x:          rand.Float32() * ScreenWidth,
-		y:          rand.Float32() * ScreenHeight,
-		r:          rand.Float32() * (3 - 1),
-		brightness: rand.Float32() * 0xff,
+		x:          rng.Float32() * width,
+		y:          rng.Float32() * height,
+		r:          rng.Float32() * (3 - 1),
+		brightness: rng.Float32() * 0xff,
 	}
 }
 
@@ -36,10 +40,34 @@ func (s *Star) Draw(screen *ebiten.Image) {
 
 func (s *Star) Update() {}
 
-func GenerateStars(n int) []*Star {
+// starDensity is the share of each starfield drawn, following the starfield
+// density setting.
+var starDensity = 1.0
+
+// drawStars draws as many of stars as the starfield density allows. Stars
+// are placed at random, so any leading share of them is spread as evenly as
+// the whole field.
+func drawStars(screen *ebiten.Image, stars []*Star) {
+	for _, s := range stars[:int(float64(len(stars))*starDensity)] {
+		s.Draw(screen)
+	}
+}
+
+// generateBackdrop fills a view of the given size with as many stars per
+// pixel as a scene has on the playfield.
+func generateBackdrop(rng *rand.Rand, width, height float64) []*Star {
+	n := int(numberOfStars * width * height / (ScreenWidth * ScreenHeight))
+	stars := make([]*Star, n)
+	for i := range n {
+		stars[i] = newStarIn(rng, float32(width), float32(height))
+	}
+	return stars
+}
+
+func GenerateStars(rng *rand.Rand, n int) []*Star {
 	stars := make([]*Star, n)
 	for i := range n {
-		stars[i] = NewStar()
+		stars[i] = NewStar(rng)
 	}
 	return stars
 }
\ No newline at end of file
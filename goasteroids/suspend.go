@@ -0,0 +1,176 @@
+package goasteroids
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-asteroids/sim"
+	"log"
+	"math/rand/v2"
+	"os"
+	"time"
+)
+
+const suspendVersion = 1
+
+// suspendFile is a game put aside part way through. Alongside the world it
+// keeps everything needed for the resumed game to carry on as if it had never
+// stopped: the cosmetic random stream and the starfield drawn from it, the
+// replay and ghost recorded so far and the pace of the heartbeat.
+type suspendFile struct {
+	Version      int           `json:"version"`
+	Saved        time.Time     `json:"saved"`
+	World        *sim.Snapshot `json:"world"`
+	CosmeticRNG  []byte        `json:"cosmetic_rng"`
+	Stars        []starState   `json:"stars"`
+	Replay       []byte        `json:"replay"`
+	Track        []byte        `json:"track"`
+	BeatWaitTime int           `json:"beat_wait_time"`
+	PlayBeatOne  bool          `json:"play_beat_one"`
+	RulesChanged bool          `json:"rules_changed,omitempty"`
+}
+
+// starState is a star of the starfield as it is saved.
+type starState struct {
+	X          float32 `json:"x"`
+	Y          float32 `json:"y"`
+	R          float32 `json:"r"`
+	Brightness float32 `json:"brightness"`
+}
+
+func getSuspendFile() (string, error) {
+	return getDataFile("suspended.json")
+}
+
+// hasSuspendedGame reports whether there is a game to continue.
+func hasSuspendedGame() bool {
+	path, err := getSuspendFile()
+	if err != nil {
+		return false
+	}
+	_, err = loadSaveFile(path)
+	return err == nil
+}
+
+// suspend saves the game to the save slot, replacing any game already there.
+func (g *GameScene) suspend() error {
+	snapshot, err := g.world.Snapshot()
+	if err != nil {
+		return err
+	}
+
+	cosmetic, err := g.rngSource.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("failed to save random number generator: %w", err)
+	}
+
+	stars := make([]starState, len(g.stars))
+	for i, s := range g.stars {
+		stars[i] = starState{X: s.x, Y: s.y, R: s.r, Brightness: s.brightness}
+	}
+
+	var replay bytes.Buffer
+	if _, err := g.recording.WriteTo(&replay); err != nil {
+		return fmt.Errorf("failed to encode replay: %w", err)
+	}
+
+	var track bytes.Buffer
+	if err := g.track.write(&track); err != nil {
+		return fmt.Errorf("failed to encode ghost: %w", err)
+	}
+
+	contents, err := json.Marshal(suspendFile{
+		Version:      suspendVersion,
+		Saved:        time.Now(),
+		World:        snapshot,
+		CosmeticRNG:  cosmetic,
+		Stars:        stars,
+		Replay:       replay.Bytes(),
+		Track:        track.Bytes(),
+		BeatWaitTime: g.beatWaitTime,
+		PlayBeatOne:  g.playBeatOne,
+		RulesChanged: g.rulesChanged,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode suspended game: %w", err)
+	}
+
+	path, err := getSuspendFile()
+	if err != nil {
+		return err
+	}
+	return writeSaveFile(path, contents)
+}
+
+// resumeGame loads the suspended game and empties the save slot, so that a
+// game can only be continued once.
+func resumeGame(audio *AudioManager) (*GameScene, error) {
+	path, err := getSuspendFile()
+	if err != nil {
+		return nil, err
+	}
+
+	contents, err := loadSaveFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read suspended game: %w", err)
+	}
+
+	var f suspendFile
+	if err := json.Unmarshal(contents, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse suspended game: %w", err)
+	}
+	if f.Version != suspendVersion {
+		return nil, fmt.Errorf("unsupported suspended game version %d", f.Version)
+	}
+	if f.World == nil {
+		return nil, errors.New("suspended game has no world")
+	}
+
+	world, err := sim.RestoreWorld(f.World)
+	if err != nil {
+		return nil, err
+	}
+
+	recording, err := sim.ReadReplay(bytes.NewReader(f.Replay))
+	if err != nil {
+		return nil, err
+	}
+
+	track, err := readGhost(f.Track)
+	if err != nil {
+		return nil, err
+	}
+
+	g := NewGameScene(world.Seed, world.Rules(), audio)
+	if err := g.rngSource.UnmarshalBinary(f.CosmeticRNG); err != nil {
+		return nil, fmt.Errorf("failed to restore random number generator: %w", err)
+	}
+	g.rng = rand.New(g.rngSource)
+	g.stars = make([]*Star, len(f.Stars))
+	for i, s := range f.Stars {
+		g.stars[i] = &Star{x: s.X, y: s.Y, r: s.R, brightness: s.Brightness}
+	}
+	g.world = world
+	g.recording = recording
+	g.track = track
+	g.beatWaitTime = f.BeatWaitTime
+	g.playBeatOne = f.PlayBeatOne
+	g.rulesChanged = f.RulesChanged
+
+	discardSuspendedGame()
+	return g, nil
+}
+
+// discardSuspendedGame empties the save slot.
+func discardSuspendedGame() {
+	path, err := getSuspendFile()
+	if err != nil {
+		return
+	}
+	for _, p := range []string{path, path + backupExt} {
+		if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Println("Error removing suspended game:", err)
+		}
+	}
+}
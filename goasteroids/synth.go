@@ -0,0 +1,129 @@
+package goasteroids
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// instrument shapes the notes of a part. Each note rises over attack, then
+// dies away with the time constant decay, or holds if decay is zero, and
+// fades out over release once it ends, with times in seconds. A detuned
+// second oscillator, detune semitones away, thickens the sound.
+type instrument struct {
+	wave    func(phase float64) float64
+	attack  float64
+	decay   float64
+	release float64
+	detune  float64
+	volume  float64
+}
+
+func sine(phase float64) float64 {
+	return math.Sin(2 * math.Pi * phase)
+}
+
+func triangle(phase float64) float64 {
+	return 4*math.Abs(phase-0.5) - 1
+}
+
+func square(phase float64) float64 {
+	if phase < 0.5 {
+		return 0.5
+	}
+	return -0.5
+}
+
+// note is a pitch, as a MIDI note number, played from beat for length beats.
+type note struct {
+	beat, length float64
+	pitch        int
+}
+
+// part is a line of music played on a single instrument.
+type part struct {
+	instrument instrument
+	notes      []note
+}
+
+// score is a loop of music beats long at bpm beats a minute. Each stem is
+// made of parts and rendered separately, so that stems can be faded in and
+// out while they play in step.
+type score struct {
+	bpm   float64
+	beats int
+	stems [][]part
+}
+
+func (s score) beatLength() float64 {
+	return 60 / s.bpm
+}
+
+// render synthesises every stem of the score as 16-bit stereo sound. Notes
+// running past the end of the loop carry on from its start, so that it loops
+// without a seam.
+func (s score) render() [][]byte {
+	frames := int(float64(s.beats) * s.beatLength() * sampleRate)
+	stems := make([][]byte, len(s.stems))
+	for i, parts := range s.stems {
+		mix := make([]float64, frames)
+		for _, p := range parts {
+			renderPart(mix, p, s.beatLength())
+		}
+
+		pcm := make([]byte, frames*bytesPerFrame)
+		for f, v := range mix {
+			sample := uint16(int16(max(-1, min(v, 1)) * math.MaxInt16))
+			binary.LittleEndian.PutUint16(pcm[f*bytesPerFrame:], sample)
+			binary.LittleEndian.PutUint16(pcm[f*bytesPerFrame+2:], sample)
+		}
+		stems[i] = pcm
+	}
+	return stems
+}
+
+func renderPart(mix []float64, p part, beatLength float64) {
+	in := p.instrument
+	// The decay is applied a sample at a time, by the fraction it falls
+	// over one.
+	fall := 1.0
+	if in.decay > 0 {
+		fall = math.Exp(-1 / (in.decay * sampleRate))
+	}
+
+	for _, n := range p.notes {
+		start := int(n.beat * beatLength * sampleRate)
+		length := n.length * beatLength
+		frames := int((length + in.release) * sampleRate)
+		step := 440 * math.Pow(2, float64(n.pitch-69)/12) / sampleRate
+		detunedStep := step * math.Pow(2, in.detune/12)
+
+		var phase, detunedPhase float64
+		decay := 1.0
+		for i := range frames {
+			t := float64(i) / sampleRate
+			level := decay * in.volume
+			if t < in.attack {
+				level *= t / in.attack
+			}
+			if t > length {
+				level *= 1 - (t-length)/in.release
+			}
+
+			v := in.wave(phase)
+			if in.detune != 0 {
+				v = (v + in.wave(detunedPhase)) / 2
+			}
+			mix[(start+i)%len(mix)] += v * level
+
+			phase, detunedPhase = wrapPhase(phase+step), wrapPhase(detunedPhase+detunedStep)
+			decay *= fall
+		}
+	}
+}
+
+func wrapPhase(phase float64) float64 {
+	if phase >= 1 {
+		return phase - 1
+	}
+	return phase
+}
@@ -1,36 +1,73 @@
 package goasteroids
 
 import (
+	"fmt"
 	"go-asteroids/assets"
+	"go-asteroids/sim"
 	"image/color"
 	"log"
+	"math/rand/v2"
+	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/hajimehoshi/ebiten/v2/text/v2"
 )
 
 type TitleScene struct {
-	meteors     map[int]*Meteor
-	meteorCount int
-	stars       []*Star
+	// input is read for the keys and buttons the prompts ask for.
+	input            *Input
+	rng              *rand.Rand
+	meteors          map[int]*sim.Meteor
+	meteorCount      int
+	stars            []*Star
+	gamepadConnected bool
+	gamepadJoined    bool
+	lastReplay       string
+	canContinue      bool
+	// showScores alternates the title with the leaderboard, flipping each
+	// time cycleTimer runs out.
+	showScores bool
+	cycleTimer *Timer
 }
 
+const titleCycleTime = 6 * time.Second
+
+func NewTitleScene(input *Input) *TitleScene {
+	rng := newRand()
+	lastReplay, err := latestReplay()
+	if err != nil {
+		log.Println("Error finding replays:", err)
+	}
+
+	return &TitleScene{
+		input:       input,
+		rng:         rng,
+		meteors:     make(map[int]*sim.Meteor),
+		stars:       GenerateStars(rng, numberOfStars),
+		lastReplay:  lastReplay,
+		canContinue: hasSuspendedGame(),
+		cycleTimer:  NewTimer(titleCycleTime),
+	}
+}
+
+var leaderboard *Leaderboard
 var highScore int
-var originalHighScore int
 
-func init() {
-	hs, err := getHighScore()
+// loadScores reads the leaderboard from the data directory.
+func loadScores() {
+	l, err := loadLeaderboard()
 	if err != nil {
-		log.Println("Error getting high score", err)
+		log.Println("Error loading leaderboard:", err)
 	}
-	highScore = hs
-	originalHighScore = highScore
+	leaderboard = l
+	highScore = leaderboard.Best()
 }
 
 func (t *TitleScene) Draw(screen *ebiten.Image) {
-	for _, s := range t.stars {
-		s.Draw(screen)
+	drawStars(screen, t.stars)
+
+	if t.showScores {
+		drawLeaderboard(screen)
 	}
 
 	textToDraw := "1 coin 1 play"
@@ -48,18 +85,74 @@ func (t *TitleScene) Draw(screen *ebiten.Image) {
 		Size:   48,
 	}, op)
 
+	drawText(screen, fmt.Sprintf("PRESS %s FOR CONTROLS, %s FOR OPTIONS", t.input.Prompt(ActionControls), t.input.Prompt(ActionOptions)), assets.ScoreFont, 16, float64(ScreenWidth)/2, float64(ScreenHeight)-120, text.AlignCenter, color.White)
+
+	if t.canContinue {
+		drawText(screen, fmt.Sprintf("%s TO CONTINUE, %s FOR A NEW GAME", t.input.Prompt(ActionConfirm), t.input.Prompt(ActionNewGame)), assets.ScoreFont, 16, float64(ScreenWidth)/2, float64(ScreenHeight)-240, text.AlignCenter, selectedColor)
+	}
+
+	if t.lastReplay != "" {
+		drawText(screen, fmt.Sprintf("PRESS %s TO WATCH THE LAST GAME", t.input.Prompt(ActionReplay)), assets.ScoreFont, 16, float64(ScreenWidth)/2, float64(ScreenHeight)-150, text.AlignCenter, color.White)
+	}
+
+	switch {
+	case t.gamepadJoined:
+		drawText(screen, "CONTROLLER READY", assets.ScoreFont, 16, float64(ScreenWidth)/2, float64(ScreenHeight)-90, text.AlignCenter, color.White)
+	case t.gamepadConnected:
+		drawText(screen, "PRESS START TO JOIN", assets.ScoreFont, 16, float64(ScreenWidth)/2, float64(ScreenHeight)-90, text.AlignCenter, selectedColor)
+	}
+
 	for _, m := range t.meteors {
-		m.Draw(screen)
+		drawMeteor(screen, m)
 	}
 }
 
+func (t *TitleScene) music() MusicTrack { return MusicTitle }
+
 func (t *TitleScene) Update(state *State) error {
-	if inpututil.IsKeyJustPressed(ebiten.KeySpace) {
-		state.SceneManager.GoToScene(NewGameScene())
+	t.gamepadConnected = state.Input.IsGamepadConnected()
+	t.gamepadJoined = state.Input.HasGamepad()
+
+	t.cycleTimer.Update()
+	if t.cycleTimer.IsReady() {
+		t.cycleTimer.Reset()
+		t.showScores = !t.showScores && len(leaderboard.Entries) > 0
+	}
+
+	switch {
+	case state.Input.IsJustPressed(ActionConfirm) && t.canContinue:
+		g, err := resumeGame(state.Audio)
+		if err != nil {
+			log.Println("Error resuming game:", err)
+			discardSuspendedGame()
+			t.canContinue = false
+			break
+		}
+		state.SceneManager.GoToScene(g)
+	case state.Input.IsJustPressed(ActionConfirm), state.Input.IsJustPressed(ActionNewGame):
+		state.SceneManager.GoToScene(NewGameScene(state.Options.gameSeed(), state.Options.Rules, state.Audio), Iris{Length: defaultTransitionTicks})
+	}
+
+	if state.Input.IsJustPressed(ActionControls) {
+		state.SceneManager.Push(NewControlsScene(state.Input.Bindings().Clone()))
+	}
+
+	if state.Input.IsJustPressed(ActionOptions) {
+		state.SceneManager.Push(NewOptionsScene(state.Settings))
+	}
+
+	if state.Input.IsJustPressed(ActionReplay) && t.lastReplay != "" {
+		r, err := loadReplay(t.lastReplay)
+		if err != nil {
+			log.Println("Error loading replay:", err)
+			t.lastReplay = ""
+		} else {
+			state.SceneManager.Push(NewReplayScene(r, state.Audio))
+		}
 	}
 
 	if len(t.meteors) < 10 {
-		m := NewMeteor(0.25, &GameScene{}, len(t.meteors)-1)
+		m := sim.NewMeteor(t.rng, state.Options.Rules.Tuning.Meteors.BaseVelocity, len(t.meteors)-1)
 		t.meteorCount++
 		t.meteors[t.meteorCount] = m
 	}
@@ -69,3 +162,19 @@ func (t *TitleScene) Update(state *State) error {
 	}
 	return nil
 }
+
+// drawLeaderboard draws the leaderboard table in the upper half of the screen.
+func drawLeaderboard(screen *ebiten.Image) {
+	drawText(screen, "HIGH SCORES", assets.TitleFont, 48, ScreenWidth/2, 60, text.AlignCenter, color.White)
+
+	y := 140.0
+	for i, e := range leaderboard.Entries {
+		drawText(screen, fmt.Sprintf("%2d", i+1), assets.ScoreFont, 16, ScreenWidth/2-300, y, text.AlignEnd, color.White)
+		drawText(screen, e.Initials, assets.ScoreFont, 16, ScreenWidth/2-270, y, text.AlignStart, selectedColor)
+		drawText(screen, fmt.Sprintf("%06d", e.Score), assets.ScoreFont, 16, ScreenWidth/2-80, y, text.AlignEnd, color.White)
+		drawText(screen, fmt.Sprintf("LVL %d", e.Level), assets.ScoreFont, 16, ScreenWidth/2-40, y, text.AlignStart, color.White)
+		drawText(screen, e.Duration(), assets.ScoreFont, 16, ScreenWidth/2+130, y, text.AlignEnd, color.White)
+		drawText(screen, e.Date.Format("2006-01-02"), assets.ScoreFont, 16, ScreenWidth/2+300, y, text.AlignEnd, color.White)
+		y += 30
+	}
+}
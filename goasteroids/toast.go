@@ -0,0 +1,61 @@
+package goasteroids
+
+import (
+	"go-asteroids/assets"
+	"image/color"
+	"strings"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+const (
+	toastDuration   = 5 * time.Second
+	toastLineHeight = 22.0
+	toastPadding    = 12.0
+)
+
+var errorColor = color.RGBA{R: 0xff, G: 0x55, B: 0x55, A: 0xff}
+
+// Toast is a short message drawn over whatever scene is showing.
+type Toast struct {
+	lines []string
+	clr   color.Color
+	timer *Timer
+}
+
+// Show replaces the current message with message, which may span several
+// lines.
+func (t *Toast) Show(message string, clr color.Color) {
+	t.lines = strings.Split(message, "\n")
+	t.clr = clr
+	t.timer = NewTimer(toastDuration)
+}
+
+func (t *Toast) Update() {
+	if t.timer == nil {
+		return
+	}
+	t.timer.Update()
+	if t.timer.IsReady() {
+		t.timer = nil
+	}
+}
+
+// Draw draws the toast across the top of the view.
+func (t *Toast) Draw(screen *ebiten.Image, view viewport) {
+	if t.timer == nil {
+		return
+	}
+
+	height := float32(len(t.lines))*toastLineHeight + 2*toastPadding
+	vector.DrawFilledRect(screen, 40, 20, float32(view.width)-80, height, color.RGBA{A: 0xc0}, false)
+
+	x, y := view.anchor(anchorTop, 0, 20+toastPadding)
+	for _, line := range t.lines {
+		drawText(screen, line, assets.ScoreFont, 14, x, y, text.AlignCenter, t.clr)
+		y += toastLineHeight
+	}
+}
@@ -0,0 +1,129 @@
+package goasteroids
+
+import (
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// defaultTransitionTicks is the length of the crossfade used when no
+// transition is given.
+const defaultTransitionTicks = 25
+
+var defaultTransition Transition = Crossfade{Length: defaultTransitionTicks}
+
+// Transition draws the change from one scene to another. Neither scene is
+// updated while it runs.
+type Transition interface {
+	// Ticks is how long the transition lasts. Zero or less switches at once.
+	Ticks() int
+	// Draw draws the transition with progress running from 0, when only from
+	// is showing, to 1, when only to is.
+	Draw(screen, from, to *ebiten.Image, progress float32)
+}
+
+// Instant switches scenes without a transition.
+type Instant struct{}
+
+func (Instant) Ticks() int { return 0 }
+
+func (Instant) Draw(screen, _, to *ebiten.Image, _ float32) {
+	screen.DrawImage(to, nil)
+}
+
+// Crossfade fades the new scene in over the old one.
+type Crossfade struct {
+	Length int
+}
+
+func (c Crossfade) Ticks() int { return c.Length }
+
+func (c Crossfade) Draw(screen, from, to *ebiten.Image, progress float32) {
+	screen.DrawImage(from, nil)
+
+	op := &ebiten.DrawImageOptions{}
+	op.ColorScale.ScaleAlpha(progress)
+	screen.DrawImage(to, op)
+}
+
+// WipeDirection is the way the edge of the new scene moves across the screen.
+type WipeDirection int
+
+const (
+	WipeRight WipeDirection = iota
+	WipeLeft
+	WipeDown
+	WipeUp
+)
+
+// Wipe uncovers the new scene behind an edge sweeping across the screen.
+type Wipe struct {
+	Length    int
+	Direction WipeDirection
+}
+
+func (w Wipe) Ticks() int { return w.Length }
+
+func (w Wipe) Draw(screen, from, to *ebiten.Image, progress float32) {
+	screen.DrawImage(from, nil)
+
+	b := to.Bounds()
+	r := b
+	switch w.Direction {
+	case WipeRight:
+		r.Max.X = b.Min.X + int(float32(b.Dx())*progress)
+	case WipeLeft:
+		r.Min.X = b.Max.X - int(float32(b.Dx())*progress)
+	case WipeDown:
+		r.Max.Y = b.Min.Y + int(float32(b.Dy())*progress)
+	case WipeUp:
+		r.Min.Y = b.Max.Y - int(float32(b.Dy())*progress)
+	}
+	if r.Empty() {
+		return
+	}
+
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(float64(r.Min.X), float64(r.Min.Y))
+	screen.DrawImage(to.SubImage(r).(*ebiten.Image), op)
+}
+
+// Iris opens a circle from the middle of the screen with the new scene inside
+// it.
+type Iris struct {
+	Length int
+}
+
+func (i Iris) Ticks() int { return i.Length }
+
+func (i Iris) Draw(screen, from, to *ebiten.Image, progress float32) {
+	screen.DrawImage(from, nil)
+
+	b := to.Bounds()
+	cx, cy := float32(b.Dx())/2, float32(b.Dy())/2
+	radius := progress * float32(math.Hypot(float64(cx), float64(cy)))
+	if radius <= 0 {
+		return
+	}
+
+	var path vector.Path
+	path.Arc(cx, cy, radius, 0, 2*math.Pi, vector.Clockwise)
+	path.Close()
+
+	// The circle samples the new scene at the same place it is drawn, so that
+	// it is a window onto it.
+	vertices, indices := path.AppendVerticesAndIndicesForFilling(nil, nil)
+	for k := range vertices {
+		vertices[k].SrcX = vertices[k].DstX
+		vertices[k].SrcY = vertices[k].DstY
+		vertices[k].ColorR = 1
+		vertices[k].ColorG = 1
+		vertices[k].ColorB = 1
+		vertices[k].ColorA = 1
+	}
+	screen.DrawTriangles(vertices, indices, to, &ebiten.DrawTrianglesOptions{
+		FillRule:  ebiten.FillRuleNonZero,
+		AntiAlias: true,
+	})
+}
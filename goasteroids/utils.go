@@ -2,14 +2,14 @@ package goasteroids
 
 import (
 	"fmt"
+	"image/color"
 	"os"
 	"os/user"
 	"path/filepath"
 	"runtime"
-	"strconv"
-	"strings"
 
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text/v2"
 )
 
 func HalfOfTheImage(image *ebiten.Image) (float64, float64) {
@@ -19,7 +19,29 @@ func HalfOfTheImage(image *ebiten.Image) (float64, float64) {
 	return halfW, halfH
 }
 
+// drawText draws s with its anchor point at x, y using the given alignment.
+func drawText(screen *ebiten.Image, s string, source *text.GoTextFaceSource, size float64, x, y float64, align text.Align, clr color.Color) {
+	op := &text.DrawOptions{
+		LayoutOptions: text.LayoutOptions{
+			PrimaryAlign: align,
+		},
+	}
+	op.ColorScale.ScaleWithColor(clr)
+	op.GeoM.Translate(x, y)
+	text.Draw(screen, s, &text.GoTextFace{
+		Source: source,
+		Size:   size,
+	}, op)
+}
+
+// dataDir replaces the app data directory when set.
+var dataDir string
+
 func getAppDataDir() (string, error) {
+	if dataDir != "" {
+		return dataDir, nil
+	}
+
 	u, err := user.Current()
 	if err != nil {
 		return "", err
@@ -35,54 +57,17 @@ func getAppDataDir() (string, error) {
 	}
 }
 
-func getHighScore() (int, error) {
-	dir, err := getAppDataDir()
-	if err != nil {
-		return 0, fmt.Errorf("failed to get app data directory: %w", err)
-	}
-
-	// Create directory with all parent directories
-	if err := os.MkdirAll(dir, 0750); err != nil {
-		return 0, fmt.Errorf("failed to create directory %s: %w", dir, err)
-	}
-
-	scoreFile := filepath.Join(dir, "highscore.txt")
-	if _, err := os.Stat(scoreFile); os.IsNotExist(err) {
-		err := os.WriteFile(scoreFile, []byte("0"), 0644)
-		if err != nil {
-			return 0, fmt.Errorf("failed to create highscore file: %w", err)
-		}
-	}
-
-	contents, err := os.ReadFile(scoreFile)
-	if err != nil {
-		return 0, fmt.Errorf("failed to read highscore file: %w", err)
-	}
-
-	score := strings.TrimSpace(string(contents))
-	s, err := strconv.Atoi(score)
-	if err != nil {
-		return 0, fmt.Errorf("failed to convert highscore to integer: %w", err)
-	}
-	return s, nil
-}
-
-func updateHighScore(score int) error {
+// getDataFile returns the path of the named file in the app data directory,
+// creating the directory if needed.
+func getDataFile(name string) (string, error) {
 	dir, err := getAppDataDir()
 	if err != nil {
-		return fmt.Errorf("failed to get app data directory: %w", err)
+		return "", fmt.Errorf("failed to get app data directory: %w", err)
 	}
 
-	// Ensure directory exists
 	if err := os.MkdirAll(dir, 0750); err != nil {
-		return fmt.Errorf("failed to create directory %s: %w", dir, err)
+		return "", fmt.Errorf("failed to create directory %s: %w", dir, err)
 	}
 
-	scoreFile := filepath.Join(dir, "highscore.txt")
-	s := fmt.Sprintf("%d", score)
-	err = os.WriteFile(scoreFile, []byte(s), 0644)
-	if err != nil {
-		return fmt.Errorf("failed to update highscore file: %w", err)
-	}
-	return nil
+	return filepath.Join(dir, name), nil
 }
@@ -1,13 +1,5 @@
 package goasteroids
 
-import "math"
+import "go-asteroids/sim"
 
-type Vector struct {
-	X float64
-	Y float64
-}
-
-func (v Vector) Normalize() Vector {
-	magnitude := math.Sqrt(v.X*v.X + v.Y*v.Y)
-	return Vector{X: v.X / magnitude, Y: v.Y / magnitude}
-}
+type Vector = sim.Vector
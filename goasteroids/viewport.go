@@ -0,0 +1,206 @@
+package goasteroids
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// AspectMode decides what is shown when the window is not the shape of the
+// playfield.
+type AspectMode int
+
+const (
+	// AspectLetterbox shows the playfield alone with black bars around it.
+	AspectLetterbox AspectMode = iota
+	// AspectExtend widens or heightens the view to fill the window. The
+	// playfield stays the same size in the middle of it, with stars around.
+	AspectExtend
+)
+
+var aspectModeNames = [...]string{
+	AspectLetterbox: "letterbox",
+	AspectExtend:    "extend",
+}
+
+func (a AspectMode) MarshalText() ([]byte, error) {
+	if a < 0 || int(a) >= len(aspectModeNames) {
+		return nil, fmt.Errorf("unknown aspect mode %d", a)
+	}
+	return []byte(aspectModeNames[a]), nil
+}
+
+func (a *AspectMode) UnmarshalText(b []byte) error {
+	for i, name := range aspectModeNames {
+		if name == string(b) {
+			*a = AspectMode(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown aspect mode %q", b)
+}
+
+// ScaleMode decides how the view is scaled up to the window.
+type ScaleMode int
+
+const (
+	// ScaleSmooth fills as much of the window as possible with a filtered
+	// image.
+	ScaleSmooth ScaleMode = iota
+	// ScaleInteger only scales by whole numbers, keeping every pixel sharp
+	// at the cost of a wider border.
+	ScaleInteger
+)
+
+var scaleModeNames = [...]string{
+	ScaleSmooth:  "smooth",
+	ScaleInteger: "integer",
+}
+
+func (s ScaleMode) MarshalText() ([]byte, error) {
+	if s < 0 || int(s) >= len(scaleModeNames) {
+		return nil, fmt.Errorf("unknown scale mode %d", s)
+	}
+	return []byte(scaleModeNames[s]), nil
+}
+
+func (s *ScaleMode) UnmarshalText(b []byte) error {
+	for i, name := range scaleModeNames {
+		if name == string(b) {
+			*s = ScaleMode(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown scale mode %q", b)
+}
+
+// hudAnchor is the edge or corner of the view a HUD element is placed
+// against.
+type hudAnchor int
+
+const (
+	anchorTopLeft hudAnchor = iota
+	anchorTop
+	anchorTopRight
+	anchorBottomLeft
+	anchorBottom
+	anchorBottomRight
+)
+
+// viewport maps the logical playfield onto the window. Scenes draw the
+// playfield at its fixed size, which is placed in a view at least as large,
+// and the view is then scaled onto the window.
+type viewport struct {
+	// width and height are the logical size of the view.
+	width, height float64
+	// playfieldX and playfieldY are where the playfield sits in the view.
+	playfieldX, playfieldY float64
+	// scale, offsetX and offsetY place the view on the window.
+	scale            float64
+	offsetX, offsetY float64
+	filter           ebiten.Filter
+	// hudOpacity fades the heads-up displays of the scenes.
+	hudOpacity float32
+}
+
+func newViewport(screenWidth, screenHeight int, settings *Settings) viewport {
+	aspect, scaling := settings.Aspect, settings.Scaling
+	v := viewport{
+		width:      ScreenWidth,
+		height:     ScreenHeight,
+		filter:     ebiten.FilterLinear,
+		hudOpacity: float32(settings.HUDOpacity),
+	}
+	sw, sh := float64(screenWidth), float64(screenHeight)
+	if sw <= 0 || sh <= 0 {
+		v.scale = 1
+		return v
+	}
+
+	if aspect == AspectExtend {
+		if sw/sh > float64(ScreenWidth)/ScreenHeight {
+			v.width = math.Round(ScreenHeight * sw / sh)
+		} else {
+			v.height = math.Round(ScreenWidth * sh / sw)
+		}
+	}
+	v.playfieldX = math.Floor((v.width - ScreenWidth) / 2)
+	v.playfieldY = math.Floor((v.height - ScreenHeight) / 2)
+
+	v.scale = min(sw/v.width, sh/v.height)
+	if scaling == ScaleInteger && v.scale >= 1 {
+		v.scale = math.Floor(v.scale)
+		v.filter = ebiten.FilterNearest
+	}
+
+	v.offsetX = math.Floor((sw - v.width*v.scale) / 2)
+	v.offsetY = math.Floor((sh - v.height*v.scale) / 2)
+	return v
+}
+
+// extended reports whether the view shows more than the playfield.
+func (v viewport) extended() bool {
+	return v.width > ScreenWidth || v.height > ScreenHeight
+}
+
+// anchor returns the position in the view dx and dy in from the given edge
+// or corner. Horizontally centred anchors move right with dx.
+func (v viewport) anchor(a hudAnchor, dx, dy float64) (x, y float64) {
+	switch a {
+	case anchorTopLeft, anchorBottomLeft:
+		x = dx
+	case anchorTop, anchorBottom:
+		x = v.width/2 + dx
+	case anchorTopRight, anchorBottomRight:
+		x = v.width - dx
+	}
+
+	switch a {
+	case anchorTopLeft, anchorTop, anchorTopRight:
+		y = dy
+	default:
+		y = v.height - dy
+	}
+	return x, y
+}
+
+var playfieldBorderColor = color.Gray{Y: 0x30}
+
+// drawPlayfield draws the playfield image into the view. Unless it is drawn
+// over another scene, it covers the stars of an extended view behind it and
+// outlines where the playfield ends.
+func (v viewport) drawPlayfield(dst, playfield *ebiten.Image, bottom bool) {
+	if bottom && v.extended() {
+		x, y := float32(v.playfieldX), float32(v.playfieldY)
+		vector.DrawFilledRect(dst, x, y, ScreenWidth, ScreenHeight, color.Black, false)
+		vector.StrokeRect(dst, x, y, ScreenWidth, ScreenHeight, 1, playfieldBorderColor, false)
+	}
+
+	op := &ebiten.DrawImageOptions{}
+	op.GeoM.Translate(v.playfieldX, v.playfieldY)
+	dst.DrawImage(playfield, op)
+}
+
+// present draws the finished view onto the window.
+func (v viewport) present(screen, view *ebiten.Image) {
+	op := &ebiten.DrawImageOptions{Filter: v.filter}
+	op.GeoM.Scale(v.scale, v.scale)
+	op.GeoM.Translate(v.offsetX, v.offsetY)
+	screen.DrawImage(view, op)
+}
+
+// fitImage returns img if it is already the size of the view, or a new image
+// that is.
+func fitImage(img *ebiten.Image, v viewport) *ebiten.Image {
+	w, h := int(v.width), int(v.height)
+	if img != nil {
+		if b := img.Bounds(); b.Dx() == w && b.Dy() == h {
+			return img
+		}
+		img.Deallocate()
+	}
+	return ebiten.NewImage(w, h)
+}
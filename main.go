@@ -1,20 +1,114 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"go-asteroids/goasteroids"
+	"go-asteroids/sim"
 	"log"
+	"os"
 
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
+// windowSize is a width and height given on the command line as WxH.
+type windowSize struct {
+	width, height int
+}
+
+func (s *windowSize) String() string {
+	return fmt.Sprintf("%dx%d", s.width, s.height)
+}
+
+func (s *windowSize) Set(value string) error {
+	var w, h int
+	if _, err := fmt.Sscanf(value, "%dx%d", &w, &h); err != nil || w <= 0 || h <= 0 {
+		return fmt.Errorf("expected WIDTHxHEIGHT, such as 1280x960")
+	}
+	s.width, s.height = w, h
+	return nil
+}
+
 func main() {
-	ebiten.SetWindowTitle("Go Asteroids")
-	ebiten.SetWindowSize(goasteroids.ScreenWidth, goasteroids.ScreenHeight)
+	options := goasteroids.DefaultOptions()
+	size := windowSize{goasteroids.ScreenWidth, goasteroids.ScreenHeight}
+
+	windowed := flag.Bool("windowed", false, "open a window instead of running fullscreen; overrides the saved setting when given, so -windowed=false runs fullscreen")
+	flag.Var(&size, "size", "window size as WIDTHxHEIGHT")
+	scale := flag.Float64("scale", 1, "multiply the window size by this factor")
+	tps := flag.Int("tps", ebiten.DefaultTPS, "game updates per second; the game speeds up or slows down with it")
+	vsync := flag.Bool("vsync", true, "wait for vertical sync before presenting each frame; overrides the saved setting when given")
+	flag.Uint64Var(&options.Seed, "seed", 0, "play every game with this seed; 0 picks a random seed per game")
+	flag.IntVar(&options.Rules.StartLevel, "level", 1, "start new games on this level")
+	flag.BoolVar(&options.Rules.GodMode, "god", false, "make the ship indestructible")
+	flag.BoolVar(&options.Mute, "mute", false, "turn off all sound")
+	flag.StringVar(&options.ReplayFile, "replay", "", "play this replay file instead of showing the title")
+	tuningFile := flag.String("tuning", "", "load gameplay tuning overrides from this JSON file")
+	levelsFile := flag.String("levels", "", "load level definitions from this JSON file")
+	flag.BoolVar(&options.WatchRules, "watch", false, "reload the tuning and levels files when they change")
+	flag.StringVar(&options.DataDir, "data-dir", "", "keep saves, settings and replays in this directory")
 
-	ebiten.SetCursorMode(ebiten.CursorModeHidden)
-	ebiten.SetFullscreen(true)
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags]\n\nFlags:\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() > 0 {
+		fmt.Fprintf(flag.CommandLine.Output(), "unexpected argument %q\n", flag.Arg(0))
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *scale <= 0 {
+		log.Fatalf("Invalid scale %v: must be greater than zero", *scale)
+	}
+	if *tps <= 0 {
+		log.Fatalf("Invalid tps %d: must be greater than zero", *tps)
+	}
+	if options.Rules.StartLevel < 1 {
+		log.Fatalf("Invalid level %d: levels start at 1", options.Rules.StartLevel)
+	}
+
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "windowed":
+			fullscreen := !*windowed
+			options.Fullscreen = &fullscreen
+		case "vsync":
+			options.Vsync = vsync
+		}
+	})
+
+	options.TuningFile = *tuningFile
+	options.LevelsFile = *levelsFile
+
+	if *tuningFile != "" {
+		tuning, err := sim.LoadTuning(*tuningFile)
+		if err != nil {
+			log.Fatalf("Invalid tuning file: %v", err)
+		}
+		options.Rules.Tuning = tuning
+	}
+
+	if *levelsFile != "" {
+		levels, err := sim.LoadLevels(*levelsFile)
+		if err != nil {
+			log.Fatalf("Invalid levels file: %v", err)
+		}
+		options.Rules.Levels = levels
+	}
+
+	ebiten.SetWindowTitle("Go Asteroids")
+	ebiten.SetWindowSize(int(float64(size.width)**scale), int(float64(size.height)**scale))
+	ebiten.SetTPS(*tps)
+	// Keep updating in the background so that a game in progress notices
+	// the window losing focus and pauses itself.
+	ebiten.SetRunnableOnUnfocused(true)
+	// Closing the window shuts the game down the same way as quitting, so
+	// that a game in progress is saved first.
+	ebiten.SetWindowClosingHandled(true)
 
-	if err := ebiten.RunGame(&goasteroids.Game{}); err != nil {
+	if err := ebiten.RunGame(goasteroids.NewGame(options)); err != nil {
 		log.Fatal(err)
 	}
 }
@@ -0,0 +1,39 @@
+package sim
+
+import (
+	"math"
+
+	"github.com/solarlune/resolv"
+)
+
+type AlienLaser struct {
+	Position Vector
+	Rotation float64
+	laserObj *resolv.ConvexPolygon
+}
+
+func NewAlienLaser(position Vector, rotation float64) *AlienLaser {
+	halfW, halfH := AlienLaserSize.Half()
+
+	position.X -= halfW
+	position.Y -= halfH
+
+	al := &AlienLaser{
+		Position: position,
+		Rotation: rotation,
+		laserObj: resolv.NewRectangle(position.X, position.Y, AlienLaserSize.W, AlienLaserSize.H),
+	}
+	al.laserObj.SetPosition(position.X, position.Y)
+	al.laserObj.Tags().Set(TagLaser)
+
+	return al
+}
+
+func (al *AlienLaser) Update(speedPerSecond float64) {
+	speed := speedPerSecond / float64(TicksPerSecond)
+
+	al.Position.X += math.Sin(al.Rotation) * speed
+	al.Position.Y += math.Cos(al.Rotation) * -speed
+
+	al.laserObj.SetPosition(al.Position.X, al.Position.Y)
+}
@@ -0,0 +1,181 @@
+package sim
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/solarlune/resolv"
+)
+
+// AlienKind is how an alien enters the playfield and aims.
+type AlienKind int
+
+const (
+	// AlienFromRight crosses from the right and shoots in random directions.
+	AlienFromRight AlienKind = iota
+	// AlienFromLeft crosses from the left and shoots in random directions.
+	AlienFromLeft
+	// AlienHunter flies at the player and aims at them.
+	AlienHunter
+)
+
+var alienKindNames = []string{
+	AlienFromRight: "right",
+	AlienFromLeft:  "left",
+	AlienHunter:    "hunter",
+}
+
+func (k AlienKind) MarshalText() ([]byte, error) {
+	if k < 0 || int(k) >= len(alienKindNames) {
+		return nil, fmt.Errorf("unknown alien kind %d", k)
+	}
+	return []byte(alienKindNames[k]), nil
+}
+
+func (k *AlienKind) UnmarshalText(text []byte) error {
+	i := slices.Index(alienKindNames, string(text))
+	if i < 0 {
+		return fmt.Errorf("unknown alien type %q, expected one of %s", text, strings.Join(alienKindNames, ", "))
+	}
+	*k = AlienKind(i)
+	return nil
+}
+
+type Alien struct {
+	Position Vector
+	Angle    float64
+	Movement Vector
+	// Variant is the index of the sprite in AlienSizes.
+	Variant       int
+	IsIntelligent bool
+	Exploding     bool
+	// Explosion plays while the alien is exploding. The alien is removed
+	// when it finishes.
+	Explosion *Animation
+	alienObj  *resolv.Circle
+}
+
+func NewAlien(kind AlienKind, baseVelocity float64, w *World) *Alien {
+	var alien Alien
+
+	variant := w.rng.IntN(len(AlienSizes))
+	radius := float64(int(AlienSizes[variant].W) / 2)
+
+	switch kind {
+	case AlienFromRight:
+		// Stupid alien that comes in from the right and shoots in random directions.
+		x := float64(PlayfieldWidth + 100)
+		y := float64(w.rng.IntN(PlayfieldHeight-100) + 100)
+
+		target := Vector{
+			X: 0,
+			Y: y,
+		}
+
+		pos := Vector{
+			X: x,
+			Y: y,
+		}
+
+		velocity := baseVelocity + w.rng.Float64()*2.5
+
+		movement := Vector{
+			X: target.X - velocity,
+			Y: 0,
+		}
+
+		alien = Alien{
+			Variant:       variant,
+			Position:      pos,
+			alienObj:      resolv.NewCircle(pos.X, pos.Y, radius),
+			Movement:      movement,
+			IsIntelligent: false,
+		}
+
+		alien.alienObj.SetPosition(pos.X, pos.Y)
+	case AlienFromLeft:
+		// Stupid alien that comes in from the left and shoots in random directions.
+		x := -100.0
+		y := float64(w.rng.IntN(PlayfieldHeight-100) + 100)
+
+		target := Vector{
+			X: 0,
+			Y: y,
+		}
+
+		pos := Vector{
+			X: x,
+			Y: y,
+		}
+
+		velocity := baseVelocity + w.rng.Float64()*2.5
+
+		movement := Vector{
+			X: target.X + velocity,
+			Y: 0,
+		}
+
+		alien = Alien{
+			Variant:       variant,
+			Position:      pos,
+			alienObj:      resolv.NewCircle(pos.X, pos.Y, radius),
+			Movement:      movement,
+			IsIntelligent: false,
+		}
+
+		alien.alienObj.SetPosition(pos.X, pos.Y)
+	case AlienHunter:
+		middle := Vector{
+			X: PlayfieldWidth / 2,
+			Y: PlayfieldHeight / 2,
+		}
+
+		angle := w.rng.Float64() * 2 * math.Pi
+		r := PlayfieldWidth / 2.0
+
+		pos := Vector{
+			X: middle.X + math.Cos(angle)*r,
+			Y: middle.Y + math.Sin(angle)*r,
+		}
+
+		velocity := baseVelocity + w.rng.Float64()*1.5
+		target := w.Player.Position
+
+		direction := Vector{
+			X: target.X - pos.X,
+			Y: target.Y - pos.Y,
+		}
+		normalizedDirection := direction.Normalize()
+
+		movement := Vector{
+			X: normalizedDirection.X * velocity,
+			Y: normalizedDirection.Y * velocity,
+		}
+
+		alien = Alien{
+			Variant:       variant,
+			Position:      pos,
+			alienObj:      resolv.NewCircle(pos.X, pos.Y, radius),
+			Angle:         angle,
+			Movement:      movement,
+			IsIntelligent: true,
+		}
+
+		alien.alienObj.SetPosition(pos.X, pos.Y)
+	}
+
+	alien.alienObj.Tags().Set(TagAlien)
+	return &alien
+}
+
+func (a *Alien) Update() {
+	dx := a.Movement.X
+	dy := a.Movement.Y
+
+	a.Position.X += dx
+	a.Position.Y += dy
+
+	a.alienObj.SetPosition(a.Position.X, a.Position.Y)
+}
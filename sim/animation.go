@@ -0,0 +1,104 @@
+package sim
+
+// ExplosionFrames is the number of frames in an explosion, of the ship or of
+// anything it destroys.
+const ExplosionFrames = 12
+
+// Animation counts through the frames of a sprite animation, FrameTicks
+// ticks a frame. A looping animation starts over after its last frame. One
+// that plays once stops at the end of its last frame and calls OnFinish.
+//
+// Only the timing lives here, so that it steps with the world and anything
+// waiting on an animation happens on the same tick in every replay. The front
+// end draws the image for Frame.
+type Animation struct {
+	Frames     int
+	FrameTicks int
+	Loop       bool
+	Tick       int
+	OnFinish   func()
+}
+
+func NewAnimation(frames int, frameTime Duration, loop bool, onFinish func()) *Animation {
+	return &Animation{
+		Frames:     frames,
+		FrameTicks: frameTicks(frameTime),
+		Loop:       loop,
+		OnFinish:   onFinish,
+	}
+}
+
+// frameTicks returns the ticks a frame of d lasts, at least one.
+func frameTicks(d Duration) int {
+	return max(1, d.timer().targetTicks)
+}
+
+func (a *Animation) Update() {
+	if a.Finished() {
+		return
+	}
+
+	a.Tick++
+	if a.Loop {
+		a.Tick %= a.Frames * a.FrameTicks
+		return
+	}
+	if a.Finished() && a.OnFinish != nil {
+		a.OnFinish()
+	}
+}
+
+// Frame returns the frame showing, from 0.
+func (a *Animation) Frame() int {
+	return min(a.Tick/a.FrameTicks, a.Frames-1)
+}
+
+// Finished reports whether an animation that plays once has shown every
+// frame.
+func (a *Animation) Finished() bool {
+	return !a.Loop && a.Tick >= a.Frames*a.FrameTicks
+}
+
+// retime changes the length of the frames, keeping the animation on the frame
+// it has reached.
+func (a *Animation) retime(frameTime Duration) {
+	if a == nil {
+		return
+	}
+	ticks := frameTicks(frameTime)
+	if a.Finished() {
+		a.Tick = a.Frames * ticks
+	} else {
+		a.Tick = a.Frame()*ticks + min(a.Tick%a.FrameTicks, ticks-1)
+	}
+	a.FrameTicks = ticks
+}
+
+// AnimationState is the progress of an Animation. Its callback is attached
+// again by whatever restores it.
+type AnimationState struct {
+	Frames     int
+	FrameTicks int
+	Loop       bool
+	Tick       int
+}
+
+func animationState(a *Animation) *AnimationState {
+	if a == nil {
+		return nil
+	}
+	return &AnimationState{Frames: a.Frames, FrameTicks: a.FrameTicks, Loop: a.Loop, Tick: a.Tick}
+}
+
+func (s *AnimationState) animation(onFinish func()) *Animation {
+	if s == nil {
+		return nil
+	}
+	return &Animation{
+		Frames:     s.Frames,
+		FrameTicks: max(1, s.FrameTicks),
+		Loop:       s.Loop,
+		Tick:       s.Tick,
+		OnFinish:   onFinish,
+	}
+}
@@ -1,8 +1,8 @@
-package goasteroids
+package sim
 
 import "github.com/solarlune/resolv"
 
-func (g *GameScene) checkCollision(obj, against *resolv.Circle) bool {
+func (w *World) checkCollision(obj, against *resolv.Circle) bool {
 	if against == nil {
 		return obj.IntersectionTest(resolv.IntersectionTestSettings{
 			TestAgainst: obj.SelectTouchingCells(1).FilterShapes(),
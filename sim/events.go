@@ -0,0 +1,43 @@
+package sim
+
+type EventKind int
+
+const (
+	// EventLaserFired is emitted for every player shot.
+	EventLaserFired EventKind = iota
+	EventAlienLaserFired
+	EventExplosion
+	EventShieldUp
+	EventLifeLost
+	EventExtraLife
+	EventLevelComplete
+	EventGameOver
+	// EventShieldHit is emitted every tick something strikes the shield.
+	// Position is halfway between the ship and what struck it.
+	EventShieldHit
+	// EventHyperSpace is emitted when the ship jumps, from the centre of
+	// the ship at Origin to its centre at Position.
+	EventHyperSpace
+	// EventShipDestroyed is emitted as the ship starts exploding, after the
+	// explosion itself.
+	EventShipDestroyed
+)
+
+// Event reports something that happened during a tick so that a front end can
+// react to it, for example by playing a sound or changing scenes.
+//
+// For explosions, Position is the centre of what exploded, Movement how far
+// it was moving each tick and Radius how big it was. Large is set when a large
+// meteor explodes.
+type Event struct {
+	Kind     EventKind
+	Position Vector
+	Movement Vector
+	Radius   float64
+	Large    bool
+	Origin   Vector
+}
+
+func (w *World) emit(e Event) {
+	w.events = append(w.events, e)
+}
@@ -0,0 +1,44 @@
+package sim
+
+const (
+	PlayfieldWidth  = 1280
+	PlayfieldHeight = 720
+)
+
+type Size struct {
+	W float64
+	H float64
+}
+
+// Sprite dimensions the collision shapes are built from. They mirror the
+// images in assets/images, in the order the assets package loads them, so the
+// simulation can run without decoding any image.
+var (
+	PlayerSize     = Size{W: 112, H: 75}
+	LaserSize      = Size{W: 13, H: 37}
+	AlienLaserSize = Size{W: 13, H: 37}
+	MeteorSizes    = []Size{
+		{W: 101, H: 84}, {W: 120, H: 98}, {W: 89, H: 82}, {W: 98, H: 96},
+		{W: 101, H: 84}, {W: 120, H: 98}, {W: 89, H: 82}, {W: 98, H: 96},
+	}
+	SmallMeteorSizes = []Size{
+		{W: 43, H: 43}, {W: 45, H: 40}, {W: 43, H: 43}, {W: 45, H: 40},
+	}
+	AlienSizes = []Size{
+		{W: 93, H: 84}, {W: 104, H: 84}, {W: 103, H: 84}, {W: 97, H: 84}, {W: 82, H: 84},
+	}
+)
+
+func (s Size) Half() (float64, float64) {
+	return s.W / 2, s.H / 2
+}
+
+// center returns the middle of a sprite of size s drawn at pos.
+func (s Size) center(pos Vector) Vector {
+	halfW, halfH := s.Half()
+	return Vector{X: pos.X + halfW, Y: pos.Y + halfH}
+}
+
+func midpoint(a, b Vector) Vector {
+	return Vector{X: (a.X + b.X) / 2, Y: (a.Y + b.Y) / 2}
+}
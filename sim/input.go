@@ -0,0 +1,38 @@
+package sim
+
+// Input is the state of every ship control for a single tick. The world keeps
+// the previous snapshot itself, so callers only report what is held down.
+type Input struct {
+	RotateLeft  bool
+	RotateRight bool
+	Thrust      bool
+	Reverse     bool
+	Fire        bool
+	Shield      bool
+	HyperSpace  bool
+	// Turn is an analog rotation in [-1, 1] and ThrustAmount an analog
+	// throttle in [0, 1]. The digital controls take precedence when held.
+	Turn         float64
+	ThrustAmount float64
+}
+
+func (in Input) turn() float64 {
+	if in.RotateLeft || in.RotateRight {
+		turn := 0.0
+		if in.RotateLeft {
+			turn--
+		}
+		if in.RotateRight {
+			turn++
+		}
+		return turn
+	}
+	return max(-1, min(in.Turn, 1))
+}
+
+func (in Input) thrust() float64 {
+	if in.Thrust {
+		return 1
+	}
+	return max(0, min(in.ThrustAmount, 1))
+}
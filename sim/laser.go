@@ -0,0 +1,45 @@
+package sim
+
+import (
+	"math"
+
+	"github.com/solarlune/resolv"
+)
+
+type Laser struct {
+	Position Vector
+	Rotation float64
+	laserObj *resolv.ConvexPolygon
+}
+
+func NewLaser(pos Vector, rotation float64, index int) *Laser {
+	halfW, halfH := LaserSize.Half()
+
+	pos.X -= halfW
+	pos.Y -= halfH
+
+	l := &Laser{
+		Position: pos,
+		Rotation: rotation,
+		laserObj: resolv.NewRectangle(pos.X, pos.Y, LaserSize.W, LaserSize.H),
+	}
+
+	l.laserObj.SetPosition(pos.X, pos.Y)
+	l.laserObj.SetData(&ObjectData{
+		index: index,
+	})
+	l.laserObj.Tags().Set(TagLaser)
+
+	return l
+}
+
+func (l *Laser) Update(speedPerSecond float64) {
+	speed := speedPerSecond / float64(TicksPerSecond)
+	dx := math.Sin(l.Rotation) * speed
+	dy := math.Cos(l.Rotation) * -speed
+
+	l.Position.X += dx
+	l.Position.Y += dy
+
+	l.laserObj.SetPosition(l.Position.X, l.Position.Y)
+}
@@ -0,0 +1,267 @@
+package sim
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+//go:embed levels.json
+var defaultLevels []byte
+
+// LevelDef describes one level or a range of levels. In a levels file every
+// entry starts from the values of the entry before it, so an entry only has
+// to list what changes. Rewards are the exception and are never carried over.
+type LevelDef struct {
+	From int `json:"from"`
+	// To is the last level the entry covers. It defaults to From.
+	To           int `json:"to,omitempty"`
+	LargeMeteors int `json:"large_meteors"`
+	SmallMeteors int `json:"small_meteors"`
+	// MeteorSpeed scales the tuned base velocity of meteors.
+	MeteorSpeed float64 `json:"meteor_speed"`
+	// MeteorSpeedUp scales how much faster meteors get over the level.
+	MeteorSpeedUp float64 `json:"meteor_speed_up"`
+	// AlienChance is the chance, from 0 to 1, that an alien appears each
+	// time one is due.
+	AlienChance float64     `json:"alien_chance"`
+	AlienTypes  []AlienKind `json:"alien_types"`
+	// AlienFireRate scales how often aliens shoot.
+	AlienFireRate float64 `json:"alien_fire_rate"`
+	// ExtraLife and BonusScore are awarded on reaching the level.
+	ExtraLife  bool `json:"extra_life,omitempty"`
+	BonusScore int  `json:"bonus_score,omitempty"`
+}
+
+// ProceduralLevels continues the difficulty curve past the last authored
+// level, growing from its values.
+type ProceduralLevels struct {
+	LargeMeteorsPerLevel int     `json:"large_meteors_per_level"`
+	SmallMeteorsPerLevel int     `json:"small_meteors_per_level"`
+	MeteorSpeedPerLevel  float64 `json:"meteor_speed_per_level"`
+	// ExtraLifeEvery awards an extra life on reaching every level that is a
+	// multiple of it. Zero turns it off.
+	ExtraLifeEvery int `json:"extra_life_every"`
+}
+
+// Levels is the whole level progression.
+type Levels struct {
+	Levels     []LevelDef       `json:"levels"`
+	Procedural ProceduralLevels `json:"procedural"`
+}
+
+// Level is a single level with every value resolved against the tuning.
+type Level struct {
+	Number          int
+	LargeMeteors    int
+	SmallMeteors    int
+	MeteorVelocity  float64
+	MeteorSpeedUp   float64
+	AlienChance     float64
+	AlienTypes      []AlienKind
+	AlienAttackTime Duration
+	ExtraLife       bool
+	BonusScore      int
+}
+
+// Level returns level n, using the procedural formula past the last authored
+// level.
+func (l Levels) Level(n int, t Tuning) Level {
+	var def LevelDef
+	extra := 0
+	for _, d := range l.Levels {
+		def = d
+		if n >= d.From && n <= d.To {
+			break
+		}
+	}
+	if n > def.To {
+		extra = n - def.To
+		def.LargeMeteors += l.Procedural.LargeMeteorsPerLevel * extra
+		def.SmallMeteors += l.Procedural.SmallMeteorsPerLevel * extra
+		def.MeteorSpeed += l.Procedural.MeteorSpeedPerLevel * float64(extra)
+		def.ExtraLife = l.Procedural.ExtraLifeEvery > 0 && n%l.Procedural.ExtraLifeEvery == 0
+		def.BonusScore = 0
+	}
+
+	return Level{
+		Number:          n,
+		LargeMeteors:    def.LargeMeteors,
+		SmallMeteors:    def.SmallMeteors,
+		MeteorVelocity:  t.Meteors.BaseVelocity * def.MeteorSpeed,
+		MeteorSpeedUp:   t.Meteors.SpeedUpAmount * def.MeteorSpeedUp,
+		AlienChance:     def.AlienChance,
+		AlienTypes:      def.AlienTypes,
+		AlienAttackTime: Duration(float64(t.Aliens.AttackTime) / def.AlienFireRate),
+		ExtraLife:       def.ExtraLife,
+		BonusScore:      def.BonusScore,
+	}
+}
+
+func (l *Levels) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Levels     []json.RawMessage `json:"levels"`
+		Procedural ProceduralLevels  `json:"procedural"`
+	}
+	if err := decodeStrict(data, &raw); err != nil {
+		return err
+	}
+
+	levels := Levels{Procedural: raw.Procedural}
+	var prev LevelDef
+	for i, r := range raw.Levels {
+		def := prev
+		def.To = 0
+		def.ExtraLife = false
+		def.BonusScore = 0
+		if err := decodeStrict(r, &def); err != nil {
+			return fmt.Errorf("levels[%d]: %w", i, err)
+		}
+		if def.To == 0 {
+			def.To = def.From
+		}
+		levels.Levels = append(levels.Levels, def)
+		prev = def
+	}
+
+	*l = levels
+	return nil
+}
+
+// DefaultLevels returns the levels the game ships with.
+func DefaultLevels() Levels {
+	l, err := parseLevels(defaultLevels)
+	if err != nil {
+		panic(fmt.Sprintf("sim: embedded levels.json: %v", err))
+	}
+	return l
+}
+
+func parseLevels(data []byte) (Levels, error) {
+	var l Levels
+	if err := decodeStrict(data, &l); err != nil {
+		return l, err
+	}
+	if err := l.Validate(); err != nil {
+		return l, err
+	}
+	return l, nil
+}
+
+// LoadLevels reads a levels file, which replaces the default levels as a
+// whole.
+func LoadLevels(path string) (Levels, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return DefaultLevels(), fmt.Errorf("failed to read levels file: %w", err)
+	}
+
+	l, err := parseLevels(data)
+	if err != nil {
+		return DefaultLevels(), fmt.Errorf("%s: %w", path, err)
+	}
+	return l, nil
+}
+
+// Validate reports every problem with the levels. Authored levels must start
+// at level 1 and follow on from each other without gaps.
+func (l Levels) Validate() error {
+	var errs []error
+	if len(l.Levels) == 0 {
+		errs = append(errs, errors.New("levels must define at least level 1"))
+	}
+
+	next := 1
+	for i, d := range l.Levels {
+		fail := func(format string, args ...any) {
+			errs = append(errs, fmt.Errorf("levels[%d]: "+format, append([]any{i}, args...)...))
+		}
+
+		if d.From != next {
+			fail("from must be %d to follow on from the previous level, got %d", next, d.From)
+		}
+		if d.To < d.From {
+			fail("to must not be before from, got %d", d.To)
+		}
+		next = d.To + 1
+
+		if d.LargeMeteors < 0 || d.SmallMeteors < 0 {
+			fail("meteor counts must not be negative")
+		}
+		if d.LargeMeteors+d.SmallMeteors == 0 {
+			fail("must have at least one meteor")
+		}
+		if d.MeteorSpeed <= 0 {
+			fail("meteor_speed must be greater than 0, got %v", d.MeteorSpeed)
+		}
+		if d.MeteorSpeedUp < 0 {
+			fail("meteor_speed_up must not be negative, got %v", d.MeteorSpeedUp)
+		}
+		if d.AlienChance < 0 || d.AlienChance > 1 {
+			fail("alien_chance must be between 0 and 1, got %v", d.AlienChance)
+		}
+		if d.AlienChance > 0 && len(d.AlienTypes) == 0 {
+			fail("alien_types must list at least one type when alien_chance is above 0")
+		}
+		if d.AlienFireRate <= 0 {
+			fail("alien_fire_rate must be greater than 0, got %v", d.AlienFireRate)
+		}
+		if d.BonusScore < 0 {
+			fail("bonus_score must not be negative, got %d", d.BonusScore)
+		}
+	}
+
+	p := l.Procedural
+	if p.LargeMeteorsPerLevel < 0 || p.SmallMeteorsPerLevel < 0 || p.MeteorSpeedPerLevel < 0 {
+		errs = append(errs, errors.New("procedural: growth per level must not be negative"))
+	}
+	if p.ExtraLifeEvery < 0 {
+		errs = append(errs, fmt.Errorf("procedural: extra_life_every must not be negative, got %d", p.ExtraLifeEvery))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Rules are everything a world is balanced with.
+type Rules struct {
+	Tuning Tuning `json:"tuning"`
+	Levels Levels `json:"levels"`
+	// StartLevel is the level a new game begins on. Zero is the first level.
+	StartLevel int `json:"start_level,omitempty"`
+	// GodMode stops the ship from being destroyed, for trying out levels.
+	GodMode bool `json:"god_mode,omitempty"`
+}
+
+func DefaultRules() Rules {
+	return Rules{
+		Tuning: DefaultTuning(),
+		Levels: DefaultLevels(),
+	}
+}
+
+func (r Rules) Validate() error {
+	err := errors.Join(r.Tuning.Validate(), r.Levels.Validate())
+	if r.StartLevel < 0 {
+		err = errors.Join(err, fmt.Errorf("start_level must not be negative, got %d", r.StartLevel))
+	}
+	return err
+}
+
+// decodeStrict decodes JSON, rejecting unknown fields so that a misspelt name
+// is reported instead of silently ignored. Syntax errors carry a line number.
+func decodeStrict(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			line := bytes.Count(data[:syntaxErr.Offset], []byte("\n")) + 1
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		return err
+	}
+	return nil
+}
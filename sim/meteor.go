@@ -0,0 +1,175 @@
+package sim
+
+import (
+	"math"
+	"math/rand/v2"
+
+	"github.com/solarlune/resolv"
+)
+
+const (
+	rotationSpeedMin = -0.02
+	rotationSpeedMax = 0.02
+)
+
+type Meteor struct {
+	Position      Vector
+	Movement      Vector
+	Rotation      float64
+	Angle         float64
+	RotationSpeed float64
+	// Variant is the index of the sprite in MeteorSizes, or in
+	// SmallMeteorSizes for small meteors.
+	Variant   int
+	Small     bool
+	Exploding bool
+	// Explosion plays while the meteor is exploding. The meteor is removed
+	// when it finishes.
+	Explosion *Animation
+	meteorObj *resolv.Circle
+}
+
+func NewMeteor(rng *rand.Rand, baseVelocity float64, index int) *Meteor {
+	target := Vector{
+		X: PlayfieldWidth / 2,
+		Y: PlayfieldHeight / 2,
+	}
+
+	angle := rng.Float64() * 2 * math.Pi
+
+	r := PlayfieldWidth/2.0 + 500
+
+	pos := Vector{
+		X: target.X + math.Cos(angle)*r,
+		Y: target.Y + math.Sin(angle)*r,
+	}
+
+	velocity := baseVelocity + rng.Float64()*1.5
+
+	direction := Vector{
+		X: target.X - pos.X,
+		Y: target.Y - pos.Y,
+	}
+
+	normalizedDirection := direction.Normalize()
+
+	movement := Vector{
+		X: normalizedDirection.X * velocity,
+		Y: normalizedDirection.Y * velocity,
+	}
+
+	variant := rng.IntN(len(MeteorSizes))
+
+	meteorObj := resolv.NewCircle(pos.X, pos.Y, MeteorSizes[variant].W/2)
+
+	m := &Meteor{
+		Position:      pos,
+		Movement:      movement,
+		RotationSpeed: rotationSpeedMin + rng.Float64()*(rotationSpeedMax-rotationSpeedMin),
+		Angle:         angle,
+		Variant:       variant,
+		meteorObj:     meteorObj,
+	}
+
+	m.meteorObj.SetPosition(pos.X, pos.Y)
+	m.meteorObj.Tags().Set(TagMeteor | TagLarge)
+	m.meteorObj.SetData(&ObjectData{
+		index: index,
+	})
+
+	return m
+}
+
+func NewSmallMeteor(rng *rand.Rand, baseVelocity float64, index int) *Meteor {
+	target := Vector{
+		X: PlayfieldWidth / 2,
+		Y: PlayfieldHeight / 2,
+	}
+
+	angle := rng.Float64() * 2 * math.Pi
+
+	r := PlayfieldWidth/2.0 + 500
+
+	pos := Vector{
+		X: target.X + math.Cos(angle)*r,
+		Y: target.Y + math.Sin(angle)*r,
+	}
+
+	velocity := baseVelocity + rng.Float64()*1.5
+
+	direction := Vector{
+		X: target.X - pos.X,
+		Y: target.Y - pos.Y,
+	}
+	normalizedDirection := direction.Normalize()
+
+	movement := Vector{
+		X: normalizedDirection.X * velocity,
+		Y: normalizedDirection.Y * velocity,
+	}
+
+	variant := rng.IntN(len(SmallMeteorSizes))
+
+	meteorObj := resolv.NewCircle(pos.X, pos.Y, float64(int(SmallMeteorSizes[variant].W)/2))
+
+	m := &Meteor{
+		Position:      pos,
+		Movement:      movement,
+		RotationSpeed: rotationSpeedMin + rng.Float64()*(rotationSpeedMax-rotationSpeedMin),
+		Variant:       variant,
+		Small:         true,
+		Angle:         angle,
+		meteorObj:     meteorObj,
+	}
+
+	m.meteorObj.SetPosition(pos.X, pos.Y)
+	m.meteorObj.Tags().Set(TagMeteor | TagSmall)
+	m.meteorObj.SetData(&ObjectData{index: index})
+
+	return m
+}
+
+// SetPosition moves the meteor and its collision shape together.
+func (m *Meteor) SetPosition(pos Vector) {
+	m.Position = pos
+	m.meteorObj.SetPosition(pos.X, pos.Y)
+}
+
+// Size returns the size of the meteor's sprite.
+func (m *Meteor) Size() Size {
+	if m.Small {
+		return SmallMeteorSizes[m.Variant]
+	}
+	return MeteorSizes[m.Variant]
+}
+
+func (m *Meteor) Update() {
+	dx := m.Movement.X
+	dy := m.Movement.Y
+
+	m.Position.X += dx
+	m.Position.Y += dy
+	m.Rotation += m.RotationSpeed
+
+	m.keepOnScreen()
+	m.meteorObj.SetPosition(m.Position.X, m.Position.Y)
+}
+
+func (m *Meteor) keepOnScreen() {
+	if m.Position.X >= PlayfieldWidth {
+		m.Position.X = 0
+		m.meteorObj.SetPosition(0, m.Position.Y)
+	}
+	if m.Position.X < 0 {
+		m.Position.X = PlayfieldWidth
+		m.meteorObj.SetPosition(PlayfieldWidth, m.Position.Y)
+	}
+	if m.Position.Y >= PlayfieldHeight {
+		m.Position.Y = 0
+		m.meteorObj.SetPosition(m.Position.X, 0)
+	}
+	if m.Position.Y < 0 {
+		m.Position.Y = PlayfieldHeight
+		m.meteorObj.SetPosition(m.Position.X, PlayfieldHeight)
+	}
+}
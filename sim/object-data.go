@@ -1,4 +1,4 @@
-package goasteroids
+package sim
 
 type ObjectData struct {
 	index int
@@ -0,0 +1,281 @@
+package sim
+
+import (
+	"math"
+
+	"github.com/solarlune/resolv"
+)
+
+type Player struct {
+	world     *World
+	Rotation  float64
+	Position  Vector
+	Velocity  float64
+	Thrusting bool
+	Reversing bool
+	Shielded  bool
+	Dying     bool
+	// Explosion is the ship blowing up while it is dying.
+	Explosion       *Animation
+	LivesRemaining  int
+	ShieldRemaining int
+	playerObj       *resolv.Circle
+	acceleration    float64
+	shotsFired      int
+	shootCoolDown   *Timer
+	burstCoolDown   *Timer
+	isDead          bool
+	shieldTimer     *Timer
+	hyperSpaceTimer *Timer
+	driftTimer      *Timer
+	driftAngle      float64
+}
+
+func NewPlayer(w *World) *Player {
+	halfW, halfH := PlayerSize.Half()
+
+	pos := Vector{
+		X: PlayfieldWidth/2 - halfW,
+		Y: PlayfieldHeight/2 - halfH,
+	}
+
+	playerObj := resolv.NewCircle(pos.X, pos.Y, PlayerSize.W/2)
+
+	t := w.tuning.Player
+	p := &Player{
+		world:           w,
+		Position:        pos,
+		playerObj:       playerObj,
+		shootCoolDown:   t.ShootCoolDown.timer(),
+		burstCoolDown:   t.BurstCoolDown.timer(),
+		LivesRemaining:  t.Lives,
+		ShieldRemaining: t.Shields,
+	}
+
+	p.playerObj.SetPosition(pos.X, pos.Y)
+	p.playerObj.Tags().Set(TagPlayer)
+
+	return p
+}
+
+// Center returns the middle of the ship.
+func (p *Player) Center() Vector {
+	return PlayerSize.center(p.Position)
+}
+
+// die ends the ship's explosion, taking the life it was on.
+func (p *Player) die() {
+	p.Dying = false
+	p.isDead = true
+}
+
+// HyperSpaceReady reports whether the hyperspace jump is off cooldown.
+func (p *Player) HyperSpaceReady() bool {
+	return p.hyperSpaceTimer == nil || p.hyperSpaceTimer.IsReady()
+}
+
+func (p *Player) Update(in, prev Input) {
+	speed := p.world.tuning.Player.RotationPerSecond / float64(TicksPerSecond)
+
+	p.Rotation += speed * in.turn()
+
+	p.accelerate(in)
+
+	p.useShield(in)
+
+	p.isDoneAccelerating(in, prev)
+
+	p.reverse(in)
+
+	p.isPlayerDrifting()
+
+	p.isDriftFinished()
+
+	p.playerObj.SetPosition(p.Position.X, p.Position.Y)
+
+	p.burstCoolDown.Update()
+
+	p.shootCoolDown.Update()
+
+	p.fireLasers(in)
+
+	p.hyperSpace(in)
+
+	if p.hyperSpaceTimer != nil {
+		p.hyperSpaceTimer.Update()
+	}
+}
+
+func (p *Player) isPlayerDrifting() {
+	if p.driftTimer != nil {
+		p.keepOnScreen()
+
+		p.driftTimer.Update()
+
+		decelerationSpeed := p.Velocity / float64(TicksPerSecond) * 4
+
+		p.Position.X += math.Sin(p.driftAngle) * decelerationSpeed
+		p.Position.Y += math.Cos(p.driftAngle) * -decelerationSpeed
+		p.playerObj.SetPosition(p.Position.X, p.Position.Y)
+	}
+}
+
+func (p *Player) isDriftFinished() {
+	if p.driftTimer != nil && p.driftTimer.IsReady() {
+		p.driftTimer = nil
+		p.Velocity = 0
+	}
+}
+
+func (p *Player) hyperSpace(in Input) {
+	if in.HyperSpace && p.HyperSpaceReady() {
+		origin := p.Center()
+		for {
+			p.Position.X = float64(p.world.rng.IntN(PlayfieldWidth))
+			p.Position.Y = float64(p.world.rng.IntN(PlayfieldHeight))
+			p.playerObj.SetPosition(p.Position.X, p.Position.Y)
+
+			collision := p.world.checkCollision(p.playerObj, nil)
+			if !collision {
+				break
+			}
+		}
+
+		p.world.emit(Event{Kind: EventHyperSpace, Position: p.Center(), Origin: origin})
+
+		if p.hyperSpaceTimer == nil {
+			p.hyperSpaceTimer = p.world.tuning.Player.HyperSpaceCooldown.timer()
+		}
+		p.hyperSpaceTimer.Reset()
+	}
+}
+
+func (p *Player) useShield(in Input) {
+	if in.Shield && p.ShieldRemaining > 0 && !p.Shielded {
+		p.world.emit(Event{Kind: EventShieldUp, Position: p.Position})
+
+		p.Shielded = true
+		p.shieldTimer = p.world.tuning.Player.ShieldDuration.timer()
+		p.ShieldRemaining--
+	}
+
+	if p.shieldTimer != nil && p.Shielded {
+		p.shieldTimer.Update()
+	}
+
+	if p.shieldTimer != nil && p.shieldTimer.IsReady() {
+		p.shieldTimer = nil
+		p.Shielded = false
+	}
+}
+
+func (p *Player) fireLasers(in Input) {
+	if p.burstCoolDown.IsReady() {
+		if p.shootCoolDown.IsReady() && in.Fire {
+			p.shootCoolDown.Reset()
+			p.shotsFired++
+			if p.shotsFired <= p.world.tuning.Player.MaxShotsPerBurst {
+				halfW, halfH := PlayerSize.Half()
+				offset := p.world.tuning.Player.LaserSpawnOffset
+
+				spawnPos := Vector{
+					p.Position.X + halfW + math.Sin(p.Rotation)*offset,
+					p.Position.Y + halfH + math.Cos(p.Rotation)*-offset,
+				}
+				p.world.laserCount++
+				laser := NewLaser(spawnPos, p.Rotation, p.world.laserCount)
+				p.world.Lasers[p.world.laserCount] = laser
+				p.world.space.Add(laser.laserObj)
+
+				p.world.emit(Event{Kind: EventLaserFired, Position: spawnPos})
+			} else {
+				p.burstCoolDown.Reset()
+				p.shotsFired = 0
+			}
+		}
+	}
+}
+
+func (p *Player) isDoneAccelerating(in, prev Input) {
+	if prev.thrust() > 0 && in.thrust() == 0 {
+		if p.Velocity < p.acceleration*10 {
+			p.Velocity = p.acceleration*10 - 5.0
+		}
+
+		if p.Velocity < 0 {
+			p.Velocity = 0
+		}
+
+		p.acceleration = 0
+
+		p.driftTimer = p.world.tuning.Player.DriftTime.timer()
+
+		p.driftAngle = p.Rotation
+	}
+	p.Thrusting = in.thrust() > 0
+}
+
+func (p *Player) reverse(in Input) {
+	p.Reversing = in.Reverse
+	if in.Reverse {
+		p.driftTimer = nil
+
+		p.keepOnScreen()
+
+		speed := p.world.tuning.Player.ReverseSpeed
+		dx := math.Sin(p.Rotation) * -speed
+		dy := math.Cos(p.Rotation) * speed
+
+		p.Position.X += dx
+		p.Position.Y += dy
+
+		p.playerObj.SetPosition(p.Position.X, p.Position.Y)
+	}
+}
+
+// accelerate moves the ship forward. The top speed scales with how far the
+// throttle is open, so a half pulled trigger gives half the acceleration.
+func (p *Player) accelerate(in Input) {
+	if throttle := in.thrust(); throttle > 0 {
+		p.driftTimer = nil
+
+		p.keepOnScreen()
+
+		maxAcceleration := p.world.tuning.Player.MaxAcceleration * throttle
+
+		if p.acceleration < maxAcceleration {
+			p.acceleration = p.Velocity + 4
+		}
+
+		if p.acceleration >= maxAcceleration {
+			p.acceleration = maxAcceleration
+		}
+
+		p.Velocity = p.acceleration
+
+		dx := math.Sin(p.Rotation) * p.acceleration
+		dy := math.Cos(p.Rotation) * -p.acceleration
+
+		p.Position.X += dx
+		p.Position.Y += dy
+	}
+}
+
+func (p *Player) keepOnScreen() {
+	if p.Position.X >= float64(PlayfieldWidth) {
+		p.Position.X = 0
+		p.playerObj.SetPosition(0, p.Position.Y)
+	}
+	if p.Position.X < 0 {
+		p.Position.X = PlayfieldWidth
+		p.playerObj.SetPosition(PlayfieldWidth, p.Position.Y)
+	}
+	if p.Position.Y >= float64(PlayfieldHeight) {
+		p.Position.Y = 0
+		p.playerObj.SetPosition(p.Position.X, 0)
+	}
+	if p.Position.Y < 0 {
+		p.Position.Y = PlayfieldHeight
+		p.playerObj.SetPosition(p.Position.X, PlayfieldHeight)
+	}
+}
@@ -0,0 +1,125 @@
+package sim
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// SetRules changes the rules of a world that is already running. The level
+// is looked up again and every running timer and animation is rebuilt with
+// its new length, keeping the progress it has made. Meteors keep the speed
+// they have gained over the level so far.
+func (w *World) SetRules(r Rules) {
+	gained := w.baseVelocity - w.level.MeteorVelocity
+
+	w.rules = r
+	w.tuning = r.Tuning
+	w.level = r.Levels.Level(w.CurrentLevel, w.tuning)
+	w.baseVelocity = w.level.MeteorVelocity + gained
+
+	t := w.tuning
+	w.meteorsSpawnTimer = retime(w.meteorsSpawnTimer, t.Meteors.SpawnTime)
+	w.velocityTimer = retime(w.velocityTimer, t.Meteors.SpeedUpTime)
+	w.alienSpawnTimer = retime(w.alienSpawnTimer, t.Aliens.SpawnTime)
+	w.alienAttackTimer = retime(w.alienAttackTimer, w.level.AlienAttackTime)
+
+	p := w.Player
+	p.shootCoolDown = retime(p.shootCoolDown, t.Player.ShootCoolDown)
+	p.burstCoolDown = retime(p.burstCoolDown, t.Player.BurstCoolDown)
+	p.Explosion.retime(t.Player.DyingFrameTime)
+	p.shieldTimer = retime(p.shieldTimer, t.Player.ShieldDuration)
+	p.hyperSpaceTimer = retime(p.hyperSpaceTimer, t.Player.HyperSpaceCooldown)
+	p.driftTimer = retime(p.driftTimer, t.Player.DriftTime)
+
+	for _, m := range w.Meteors {
+		m.Explosion.retime(t.Meteors.ExplosionFrameTime)
+	}
+	for _, a := range w.Aliens {
+		a.Explosion.retime(t.Meteors.ExplosionFrameTime)
+	}
+}
+
+// retime returns a timer of the new length that has run as many ticks as old,
+// or nil if old is not running.
+func retime(old *Timer, d Duration) *Timer {
+	if old == nil {
+		return nil
+	}
+	t := d.timer()
+	t.currentTicks = min(old.currentTicks, t.targetTicks)
+	return t
+}
+
+// Diff lists every value that differs between r and other, one line per
+// value, such as "tuning.player.shoot_cooldown: 150ms -> 100ms".
+func (r Rules) Diff(other Rules) []string {
+	before, after := flattenJSON(r), flattenJSON(other)
+
+	keys := slices.Sorted(maps.Keys(before))
+	for k := range after {
+		if _, ok := before[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+
+	var changes []string
+	for _, k := range keys {
+		b, inBefore := before[k]
+		a, inAfter := after[k]
+		switch {
+		case !inBefore:
+			changes = append(changes, fmt.Sprintf("%s: added %s", k, a))
+		case !inAfter:
+			changes = append(changes, fmt.Sprintf("%s: removed", k))
+		case a != b:
+			changes = append(changes, fmt.Sprintf("%s: %s -> %s", k, b, a))
+		}
+	}
+	return changes
+}
+
+func flattenJSON(v any) map[string]string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var tree any
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return nil
+	}
+
+	out := make(map[string]string)
+	var walk func(prefix string, v any)
+	walk = func(prefix string, v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			for k, child := range v {
+				if prefix != "" {
+					k = prefix + "." + k
+				}
+				walk(k, child)
+			}
+		case []any:
+			if len(v) > 0 {
+				if _, ok := v[0].(map[string]any); !ok {
+					leaf, _ := json.Marshal(v)
+					out[prefix] = string(leaf)
+					return
+				}
+			}
+			for i, child := range v {
+				walk(fmt.Sprintf("%s[%d]", prefix, i), child)
+			}
+		case string:
+			out[prefix] = v
+		default:
+			leaf, _ := json.Marshal(v)
+			out[prefix] = string(leaf)
+		}
+	}
+	walk("", tree)
+	return out
+}
@@ -0,0 +1,217 @@
+package sim
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// ReplayVersion identifies the replay format and the game rules it was
+// recorded under. Bump it whenever either changes, so that old replays are
+// rejected instead of silently playing out a different game.
+const ReplayVersion = 4
+
+var replayMagic = [4]byte{'G', 'A', 'R', 'P'}
+
+// maxRulesSize and maxReplayTicks guard against allocating for a corrupt
+// rules length or tick count. No game runs anywhere near a day.
+const (
+	maxRulesSize   = 1 << 20
+	maxReplayTicks = 24 * 60 * 60 * TicksPerSecond
+)
+
+const (
+	frameRotateLeft uint8 = 1 << iota
+	frameRotateRight
+	frameThrust
+	frameReverse
+	frameFire
+	frameShield
+	frameHyperSpace
+)
+
+// Frame is the input of a single tick packed into three bytes. The analog
+// controls are quantized, so a live game must step with Frame.Input rather
+// than the raw Input for a replay of it to match.
+type Frame struct {
+	Buttons uint8
+	Turn    int8
+	Thrust  uint8
+}
+
+func NewFrame(in Input) Frame {
+	var f Frame
+	for _, b := range []struct {
+		pressed bool
+		bit     uint8
+	}{
+		{in.RotateLeft, frameRotateLeft},
+		{in.RotateRight, frameRotateRight},
+		{in.Thrust, frameThrust},
+		{in.Reverse, frameReverse},
+		{in.Fire, frameFire},
+		{in.Shield, frameShield},
+		{in.HyperSpace, frameHyperSpace},
+	} {
+		if b.pressed {
+			f.Buttons |= b.bit
+		}
+	}
+	f.Turn = int8(math.Round(max(-1, min(in.Turn, 1)) * math.MaxInt8))
+	f.Thrust = uint8(math.Round(max(0, min(in.ThrustAmount, 1)) * math.MaxUint8))
+	return f
+}
+
+func (f Frame) Input() Input {
+	return Input{
+		RotateLeft:   f.Buttons&frameRotateLeft != 0,
+		RotateRight:  f.Buttons&frameRotateRight != 0,
+		Thrust:       f.Buttons&frameThrust != 0,
+		Reverse:      f.Buttons&frameReverse != 0,
+		Fire:         f.Buttons&frameFire != 0,
+		Shield:       f.Buttons&frameShield != 0,
+		HyperSpace:   f.Buttons&frameHyperSpace != 0,
+		Turn:         float64(f.Turn) / math.MaxInt8,
+		ThrustAmount: float64(f.Thrust) / math.MaxUint8,
+	}
+}
+
+// Replay is everything needed to play a game again: the seed and rules of
+// the world and the input of every tick that was stepped while playing.
+type Replay struct {
+	Seed   uint64
+	Rules  Rules
+	Frames []Frame
+}
+
+// WriteTo writes the replay in its binary form. Runs of identical frames are
+// stored once with a repeat count, which keeps a typical game to a few
+// kilobytes.
+func (r *Replay) WriteTo(w io.Writer) (int64, error) {
+	bw := bufio.NewWriter(w)
+	var n int64
+
+	header := make([]byte, 0, 4+2+8+4)
+	header = append(header, replayMagic[:]...)
+	header = binary.LittleEndian.AppendUint16(header, ReplayVersion)
+	header = binary.LittleEndian.AppendUint64(header, r.Seed)
+	header = binary.LittleEndian.AppendUint32(header, uint32(len(r.Frames)))
+	written, err := bw.Write(header)
+	n += int64(written)
+	if err != nil {
+		return n, err
+	}
+
+	rules, err := json.Marshal(r.Rules)
+	if err != nil {
+		return n, err
+	}
+	written, err = bw.Write(binary.AppendUvarint(nil, uint64(len(rules))))
+	n += int64(written)
+	if err != nil {
+		return n, err
+	}
+	written, err = bw.Write(rules)
+	n += int64(written)
+	if err != nil {
+		return n, err
+	}
+
+	run := make([]byte, 0, binary.MaxVarintLen64+3)
+	for i := 0; i < len(r.Frames); {
+		f := r.Frames[i]
+		count := 1
+		for i+count < len(r.Frames) && r.Frames[i+count] == f {
+			count++
+		}
+		i += count
+
+		run = binary.AppendUvarint(run[:0], uint64(count))
+		run = append(run, f.Buttons, byte(f.Turn), f.Thrust)
+		written, err := bw.Write(run)
+		n += int64(written)
+		if err != nil {
+			return n, err
+		}
+	}
+
+	return n, bw.Flush()
+}
+
+func ReadReplay(r io.Reader) (*Replay, error) {
+	br := bufio.NewReader(r)
+
+	header := make([]byte, 4+2+8+4)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return nil, fmt.Errorf("failed to read replay header: %w", err)
+	}
+	if [4]byte(header[:4]) != replayMagic {
+		return nil, errors.New("not a replay file")
+	}
+	if v := binary.LittleEndian.Uint16(header[4:]); v != ReplayVersion {
+		return nil, fmt.Errorf("replay version %d is not supported, expected %d", v, ReplayVersion)
+	}
+
+	replay := &Replay{Seed: binary.LittleEndian.Uint64(header[6:])}
+	ticks := int(binary.LittleEndian.Uint32(header[14:]))
+	if ticks > maxReplayTicks {
+		return nil, fmt.Errorf("replay of %d ticks is too long", ticks)
+	}
+
+	size, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read replay rules: %w", err)
+	}
+	if size > maxRulesSize {
+		return nil, fmt.Errorf("replay rules of %d bytes are too large", size)
+	}
+	rules := make([]byte, size)
+	if _, err := io.ReadFull(br, rules); err != nil {
+		return nil, fmt.Errorf("failed to read replay rules: %w", err)
+	}
+	if err := decodeStrict(rules, &replay.Rules); err != nil {
+		return nil, fmt.Errorf("invalid replay rules: %w", err)
+	}
+	if err := replay.Rules.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid replay rules: %w", err)
+	}
+	replay.Frames = make([]Frame, 0, ticks)
+
+	frame := make([]byte, 3)
+	for len(replay.Frames) < ticks {
+		count, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read replay frames: %w", err)
+		}
+		if count == 0 || count > uint64(ticks-len(replay.Frames)) {
+			return nil, fmt.Errorf("replay frame run of %d ticks is out of range", count)
+		}
+		if _, err := io.ReadFull(br, frame); err != nil {
+			return nil, fmt.Errorf("failed to read replay frames: %w", err)
+		}
+
+		f := Frame{Buttons: frame[0], Turn: int8(frame[1]), Thrust: frame[2]}
+		for range count {
+			replay.Frames = append(replay.Frames, f)
+		}
+	}
+
+	return replay, nil
+}
+
+// Play steps a new world through every frame of the replay, starting the next
+// level as soon as one is completed, and returns the world as it ends.
+func (r *Replay) Play() *World {
+	w := NewWorld(r.Seed, r.Rules)
+	for _, f := range r.Frames {
+		if w.Phase == PhaseLevelComplete {
+			w.StartNextLevel()
+		}
+		w.Step(f.Input())
+	}
+	return w
+}
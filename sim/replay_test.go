@@ -0,0 +1,55 @@
+package sim
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestReplayPlaysBackTheSameGame(t *testing.T) {
+	w := NewWorld(testSeed, DefaultRules())
+	r := &Replay{Seed: testSeed, Rules: DefaultRules()}
+	for _, in := range scriptedInputs(testSeed, testTicks) {
+		if w.Phase == PhaseGameOver {
+			break
+		}
+		// A live game steps with the quantized input it records.
+		f := NewFrame(in)
+		r.Frames = append(r.Frames, f)
+		step(w, f.Input())
+	}
+
+	var buf bytes.Buffer
+	if _, err := r.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := ReadReplay(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Seed != r.Seed || !reflect.DeepEqual(decoded.Rules, r.Rules) || !reflect.DeepEqual(decoded.Frames, r.Frames) {
+		t.Fatal("decoded replay differs from the one written")
+	}
+
+	assertSameWorld(t, w, decoded.Play())
+}
+
+func TestReadReplayRejectsCorruptTickCount(t *testing.T) {
+	r := &Replay{Seed: testSeed, Rules: DefaultRules(), Frames: make([]Frame, 100)}
+	var buf bytes.Buffer
+	if _, err := r.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	data := bytes.Clone(buf.Bytes())
+	binary.LittleEndian.PutUint32(data[14:], math.MaxUint32)
+	if _, err := ReadReplay(bytes.NewReader(data)); err == nil {
+		t.Fatal("replay claiming billions of ticks was read without an error")
+	}
+
+	if _, err := ReadReplay(bytes.NewReader(buf.Bytes()[:buf.Len()-1])); err == nil {
+		t.Fatal("truncated replay was read without an error")
+	}
+}
@@ -0,0 +1,391 @@
+package sim
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/solarlune/resolv"
+)
+
+// SnapshotVersion identifies the layout of Snapshot. Bump it whenever a field
+// is added or changes meaning so that old saves are rejected.
+const SnapshotVersion = 4
+
+// TimerState is the progress of a Timer.
+type TimerState struct {
+	Current int
+	Target  int
+}
+
+func timerState(t *Timer) *TimerState {
+	if t == nil {
+		return nil
+	}
+	return &TimerState{Current: t.currentTicks, Target: t.targetTicks}
+}
+
+func (s *TimerState) timer() *Timer {
+	if s == nil {
+		return nil
+	}
+	return &Timer{currentTicks: s.Current, targetTicks: s.Target}
+}
+
+type PlayerState struct {
+	Rotation        float64
+	Position        Vector
+	Velocity        float64
+	Acceleration    float64
+	Thrusting       bool
+	Reversing       bool
+	Shielded        bool
+	Dying           bool
+	Dead            bool
+	Explosion       *AnimationState
+	LivesRemaining  int
+	ShieldRemaining int
+	ShotsFired      int
+	DriftAngle      float64
+	ShootCoolDown   *TimerState
+	BurstCoolDown   *TimerState
+	ShieldTimer     *TimerState
+	HyperSpaceTimer *TimerState
+	DriftTimer      *TimerState
+}
+
+type MeteorState struct {
+	ID            int
+	Index         int
+	Position      Vector
+	Movement      Vector
+	Rotation      float64
+	Angle         float64
+	RotationSpeed float64
+	Variant       int
+	Small         bool
+	Exploding     bool
+	Explosion     *AnimationState
+}
+
+type AlienState struct {
+	ID            int
+	Position      Vector
+	Angle         float64
+	Movement      Vector
+	Variant       int
+	IsIntelligent bool
+	Exploding     bool
+	Explosion     *AnimationState
+}
+
+type LaserState struct {
+	ID       int
+	Index    int
+	Position Vector
+	Rotation float64
+}
+
+// Snapshot is the complete state of a World between two ticks, including the
+// state of its random number generator. A world restored from it plays on
+// exactly as the original would have.
+type Snapshot struct {
+	Version         int
+	Seed            uint64
+	Rules           Rules
+	RNG             []byte
+	Tick            int
+	Score           int
+	CurrentLevel    int
+	Phase           Phase
+	BaseVelocity    float64
+	MeteorCount     int
+	LargeSpawned    int
+	SmallSpawned    int
+	LaserCount      int
+	AlienCount      int
+	AlienLaserCount int
+	PrevInput       Input
+	MeteorsSpawn    *TimerState
+	Velocity        *TimerState
+	AlienAttack     *TimerState
+	AlienSpawn      *TimerState
+	Player          PlayerState
+	Meteors         []MeteorState
+	Lasers          []LaserState
+	Aliens          []AlienState
+	AlienLasers     []LaserState
+}
+
+func (w *World) Snapshot() (*Snapshot, error) {
+	rng, err := w.pcg.MarshalBinary()
+	if err != nil {
+		return nil, fmt.Errorf("failed to save random number generator: %w", err)
+	}
+
+	p := w.Player
+	s := &Snapshot{
+		Version:         SnapshotVersion,
+		Seed:            w.Seed,
+		Rules:           w.rules,
+		RNG:             rng,
+		Tick:            w.Tick,
+		Score:           w.Score,
+		CurrentLevel:    w.CurrentLevel,
+		Phase:           w.Phase,
+		BaseVelocity:    w.baseVelocity,
+		MeteorCount:     w.meteorCount,
+		LargeSpawned:    w.largeSpawned,
+		SmallSpawned:    w.smallSpawned,
+		LaserCount:      w.laserCount,
+		AlienCount:      w.alienCount,
+		AlienLaserCount: w.alienLaserCount,
+		PrevInput:       w.prevInput,
+		MeteorsSpawn:    timerState(w.meteorsSpawnTimer),
+		Velocity:        timerState(w.velocityTimer),
+		AlienAttack:     timerState(w.alienAttackTimer),
+		AlienSpawn:      timerState(w.alienSpawnTimer),
+		Player: PlayerState{
+			Rotation:        p.Rotation,
+			Position:        p.Position,
+			Velocity:        p.Velocity,
+			Acceleration:    p.acceleration,
+			Thrusting:       p.Thrusting,
+			Reversing:       p.Reversing,
+			Shielded:        p.Shielded,
+			Dying:           p.Dying,
+			Dead:            p.isDead,
+			Explosion:       animationState(p.Explosion),
+			LivesRemaining:  p.LivesRemaining,
+			ShieldRemaining: p.ShieldRemaining,
+			ShotsFired:      p.shotsFired,
+			DriftAngle:      p.driftAngle,
+			ShootCoolDown:   timerState(p.shootCoolDown),
+			BurstCoolDown:   timerState(p.burstCoolDown),
+			ShieldTimer:     timerState(p.shieldTimer),
+			HyperSpaceTimer: timerState(p.hyperSpaceTimer),
+			DriftTimer:      timerState(p.driftTimer),
+		},
+	}
+
+	for _, id := range sortedKeys(w.Meteors) {
+		m := w.Meteors[id]
+		s.Meteors = append(s.Meteors, MeteorState{
+			ID:            id,
+			Index:         objectIndex(m.meteorObj.Data()),
+			Position:      m.Position,
+			Movement:      m.Movement,
+			Rotation:      m.Rotation,
+			Angle:         m.Angle,
+			RotationSpeed: m.RotationSpeed,
+			Variant:       m.Variant,
+			Small:         m.Small,
+			Exploding:     m.Exploding,
+			Explosion:     animationState(m.Explosion),
+		})
+	}
+
+	for _, id := range sortedKeys(w.Lasers) {
+		l := w.Lasers[id]
+		s.Lasers = append(s.Lasers, LaserState{
+			ID:       id,
+			Index:    objectIndex(l.laserObj.Data()),
+			Position: l.Position,
+			Rotation: l.Rotation,
+		})
+	}
+
+	for _, id := range sortedKeys(w.Aliens) {
+		a := w.Aliens[id]
+		s.Aliens = append(s.Aliens, AlienState{
+			ID:            id,
+			Position:      a.Position,
+			Angle:         a.Angle,
+			Movement:      a.Movement,
+			Variant:       a.Variant,
+			IsIntelligent: a.IsIntelligent,
+			Exploding:     a.Exploding,
+			Explosion:     animationState(a.Explosion),
+		})
+	}
+
+	for _, id := range sortedKeys(w.AlienLasers) {
+		l := w.AlienLasers[id]
+		s.AlienLasers = append(s.AlienLasers, LaserState{
+			ID:       id,
+			Position: l.Position,
+			Rotation: l.Rotation,
+		})
+	}
+
+	return s, nil
+}
+
+func objectIndex(data any) int {
+	if d, ok := data.(*ObjectData); ok {
+		return d.index
+	}
+	return 0
+}
+
+// RestoreWorld rebuilds the world a Snapshot was taken from.
+func RestoreWorld(s *Snapshot) (*World, error) {
+	if s.Version != SnapshotVersion {
+		return nil, fmt.Errorf("snapshot version %d is not supported, expected %d", s.Version, SnapshotVersion)
+	}
+
+	if err := s.Rules.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid snapshot rules: %w", err)
+	}
+
+	if s.CurrentLevel < 1 {
+		return nil, fmt.Errorf("snapshot level %d is out of range", s.CurrentLevel)
+	}
+
+	w := NewWorld(s.Seed, s.Rules)
+	if err := w.pcg.UnmarshalBinary(s.RNG); err != nil {
+		return nil, fmt.Errorf("failed to restore random number generator: %w", err)
+	}
+
+	timers := []*TimerState{s.MeteorsSpawn, s.Velocity, s.AlienAttack, s.AlienSpawn}
+	timers = append(timers, s.Player.ShootCoolDown, s.Player.BurstCoolDown)
+	for _, t := range timers {
+		if t == nil {
+			return nil, errors.New("snapshot is missing a timer")
+		}
+	}
+
+	w.Tick = s.Tick
+	w.Score = s.Score
+	w.CurrentLevel = s.CurrentLevel
+	w.level = w.rules.Levels.Level(w.CurrentLevel, w.tuning)
+	w.Phase = s.Phase
+	w.baseVelocity = s.BaseVelocity
+	w.meteorCount = s.MeteorCount
+	w.largeSpawned = s.LargeSpawned
+	w.smallSpawned = s.SmallSpawned
+	w.laserCount = s.LaserCount
+	w.alienCount = s.AlienCount
+	w.alienLaserCount = s.AlienLaserCount
+	w.prevInput = s.PrevInput
+	w.meteorsSpawnTimer = s.MeteorsSpawn.timer()
+	w.velocityTimer = s.Velocity.timer()
+	w.alienAttackTimer = s.AlienAttack.timer()
+	w.alienSpawnTimer = s.AlienSpawn.timer()
+
+	ps := s.Player
+	if ps.Dying && ps.Explosion == nil {
+		return nil, errors.New("snapshot has a dying player without an explosion")
+	}
+	p := w.Player
+	p.Rotation = ps.Rotation
+	p.Position = ps.Position
+	p.Velocity = ps.Velocity
+	p.acceleration = ps.Acceleration
+	p.Thrusting = ps.Thrusting
+	p.Reversing = ps.Reversing
+	p.Shielded = ps.Shielded
+	p.Dying = ps.Dying
+	p.isDead = ps.Dead
+	p.Explosion = ps.Explosion.animation(p.die)
+	p.LivesRemaining = ps.LivesRemaining
+	p.ShieldRemaining = ps.ShieldRemaining
+	p.shotsFired = ps.ShotsFired
+	p.driftAngle = ps.DriftAngle
+	p.shootCoolDown = ps.ShootCoolDown.timer()
+	p.burstCoolDown = ps.BurstCoolDown.timer()
+	p.shieldTimer = ps.ShieldTimer.timer()
+	p.hyperSpaceTimer = ps.HyperSpaceTimer.timer()
+	p.driftTimer = ps.DriftTimer.timer()
+	p.playerObj.SetPosition(p.Position.X, p.Position.Y)
+
+	for _, ms := range s.Meteors {
+		if err := checkVariant(ms.Variant, ms.Small); err != nil {
+			return nil, err
+		}
+		if ms.Exploding && ms.Explosion == nil {
+			return nil, errors.New("snapshot has an exploding meteor without an explosion")
+		}
+
+		var obj *resolv.Circle
+		if ms.Small {
+			obj = resolv.NewCircle(ms.Position.X, ms.Position.Y, float64(int(SmallMeteorSizes[ms.Variant].W)/2))
+			obj.Tags().Set(TagMeteor | TagSmall)
+		} else {
+			obj = resolv.NewCircle(ms.Position.X, ms.Position.Y, MeteorSizes[ms.Variant].W/2)
+			obj.Tags().Set(TagMeteor | TagLarge)
+		}
+		obj.SetData(&ObjectData{index: ms.Index})
+
+		m := &Meteor{
+			Position:      ms.Position,
+			Movement:      ms.Movement,
+			Rotation:      ms.Rotation,
+			Angle:         ms.Angle,
+			RotationSpeed: ms.RotationSpeed,
+			Variant:       ms.Variant,
+			Small:         ms.Small,
+			Exploding:     ms.Exploding,
+			meteorObj:     obj,
+		}
+		m.Explosion = ms.Explosion.animation(w.removeMeteor(ms.ID, m))
+		m.SetPosition(ms.Position)
+		w.space.Add(obj)
+		w.Meteors[ms.ID] = m
+	}
+
+	for _, ls := range s.Lasers {
+		obj := resolv.NewRectangle(ls.Position.X, ls.Position.Y, LaserSize.W, LaserSize.H)
+		obj.SetPosition(ls.Position.X, ls.Position.Y)
+		obj.SetData(&ObjectData{index: ls.Index})
+		obj.Tags().Set(TagLaser)
+
+		w.space.Add(obj)
+		w.Lasers[ls.ID] = &Laser{Position: ls.Position, Rotation: ls.Rotation, laserObj: obj}
+	}
+
+	for _, as := range s.Aliens {
+		if as.Variant < 0 || as.Variant >= len(AlienSizes) {
+			return nil, fmt.Errorf("alien variant %d is out of range", as.Variant)
+		}
+		if as.Exploding && as.Explosion == nil {
+			return nil, errors.New("snapshot has an exploding alien without an explosion")
+		}
+
+		obj := resolv.NewCircle(as.Position.X, as.Position.Y, float64(int(AlienSizes[as.Variant].W)/2))
+		obj.SetPosition(as.Position.X, as.Position.Y)
+		obj.Tags().Set(TagAlien)
+
+		w.space.Add(obj)
+		a := &Alien{
+			Position:      as.Position,
+			Angle:         as.Angle,
+			Movement:      as.Movement,
+			Variant:       as.Variant,
+			IsIntelligent: as.IsIntelligent,
+			Exploding:     as.Exploding,
+			alienObj:      obj,
+		}
+		a.Explosion = as.Explosion.animation(w.removeAlien(as.ID, a))
+		w.Aliens[as.ID] = a
+	}
+
+	for _, ls := range s.AlienLasers {
+		al := NewAlienLaser(ls.Position, ls.Rotation)
+		al.Position = ls.Position
+		al.laserObj.SetPosition(ls.Position.X, ls.Position.Y)
+		w.AlienLasers[ls.ID] = al
+	}
+
+	return w, nil
+}
+
+func checkVariant(variant int, small bool) error {
+	sizes := MeteorSizes
+	if small {
+		sizes = SmallMeteorSizes
+	}
+	if variant < 0 || variant >= len(sizes) {
+		return fmt.Errorf("meteor variant %d is out of range", variant)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package sim
+
+import (
+	"reflect"
+	"testing"
+)
+
+// snapshotTick is partway through the first level of the scripted game.
+const snapshotTick = 600
+
+func TestRestoredWorldPlaysOnIdentically(t *testing.T) {
+	inputs := scriptedInputs(testSeed, testTicks)
+
+	w := NewWorld(testSeed, DefaultRules())
+	for _, in := range inputs[:snapshotTick] {
+		step(w, in)
+	}
+	if w.Phase != PhasePlaying || len(w.Meteors) == 0 {
+		t.Fatalf("snapshot taken in phase %d with %d meteors, want a game in play", w.Phase, len(w.Meteors))
+	}
+
+	s, err := w.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored, err := RestoreWorld(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertSameWorld(t, w, restored)
+
+	for tick, in := range inputs[snapshotTick:] {
+		ew, er := step(w, in), step(restored, in)
+		if !reflect.DeepEqual(ew, er) {
+			t.Fatalf("tick %d after restoring: events differ:\n%+v\n%+v", snapshotTick+tick, ew, er)
+		}
+	}
+	assertSameWorld(t, w, restored)
+}
+
+func TestRestoreWorldRejectsInconsistentSnapshots(t *testing.T) {
+	inputs := scriptedInputs(testSeed, testTicks)
+	w := NewWorld(testSeed, DefaultRules())
+	for _, in := range inputs[:snapshotTick] {
+		step(w, in)
+	}
+
+	tests := []struct {
+		name    string
+		corrupt func(s *Snapshot)
+	}{
+		{"level zero", func(s *Snapshot) { s.CurrentLevel = 0 }},
+		{"dying player without explosion", func(s *Snapshot) {
+			s.Player.Dying = true
+			s.Player.Explosion = nil
+		}},
+		{"exploding meteor without explosion", func(s *Snapshot) {
+			s.Meteors[0].Exploding = true
+			s.Meteors[0].Explosion = nil
+		}},
+		{"exploding alien without explosion", func(s *Snapshot) {
+			s.Aliens = append(s.Aliens, AlienState{Exploding: true})
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := w.Snapshot()
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.corrupt(s)
+			if _, err := RestoreWorld(s); err == nil {
+				t.Error("RestoreWorld accepted the snapshot")
+			}
+		})
+	}
+}
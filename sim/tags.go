@@ -1,4 +1,4 @@
-package goasteroids
+package sim
 
 import "github.com/solarlune/resolv"
 
@@ -0,0 +1,172 @@
+package sim
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+//go:embed tuning.json
+var defaultTuning []byte
+
+// Duration is a time.Duration written as a string such as "150ms" in tuning
+// files.
+type Duration time.Duration
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+func (d Duration) timer() *Timer {
+	return NewTimer(time.Duration(d))
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return fmt.Errorf("invalid duration %q, expected a value such as \"150ms\" or \"6s\"", text)
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// Tuning holds the numbers the game is balanced with. The defaults are
+// embedded from tuning.json and a file passed to LoadTuning only needs to
+// list the values it changes.
+type Tuning struct {
+	Player  PlayerTuning `json:"player"`
+	Meteors MeteorTuning `json:"meteors"`
+	Aliens  AlienTuning  `json:"aliens"`
+}
+
+type PlayerTuning struct {
+	MaxAcceleration     float64  `json:"max_acceleration"`
+	RotationPerSecond   float64  `json:"rotation_per_second"`
+	ReverseSpeed        float64  `json:"reverse_speed"`
+	ShootCoolDown       Duration `json:"shoot_cooldown"`
+	BurstCoolDown       Duration `json:"burst_cooldown"`
+	MaxShotsPerBurst    int      `json:"max_shots_per_burst"`
+	LaserSpawnOffset    float64  `json:"laser_spawn_offset"`
+	LaserSpeedPerSecond float64  `json:"laser_speed_per_second"`
+	Lives               int      `json:"lives"`
+	MaxLives            int      `json:"max_lives"`
+	Shields             int      `json:"shields"`
+	ShieldDuration      Duration `json:"shield_duration"`
+	HyperSpaceCooldown  Duration `json:"hyperspace_cooldown"`
+	DriftTime           Duration `json:"drift_time"`
+	DyingFrameTime      Duration `json:"dying_frame_time"`
+}
+
+type MeteorTuning struct {
+	BaseVelocity  float64  `json:"base_velocity"`
+	SpeedUpAmount float64  `json:"speed_up_amount"`
+	SpeedUpTime   Duration `json:"speed_up_time"`
+	SpawnTime     Duration `json:"spawn_time"`
+	// ExplosionFrameTime is how long each frame of the explosion of a
+	// destroyed meteor or alien lasts. It is removed when the explosion ends.
+	ExplosionFrameTime Duration `json:"explosion_frame_time"`
+	// MaxSmallFromLarge is the most small meteors a large one breaks into.
+	MaxSmallFromLarge int `json:"max_small_from_large"`
+}
+
+type AlienTuning struct {
+	BaseVelocity        float64  `json:"base_velocity"`
+	SpawnTime           Duration `json:"spawn_time"`
+	AttackTime          Duration `json:"attack_time"`
+	LaserSpeedPerSecond float64  `json:"laser_speed_per_second"`
+}
+
+// DefaultTuning returns the tuning the game ships with.
+func DefaultTuning() Tuning {
+	var t Tuning
+	if err := decodeStrict(defaultTuning, &t); err != nil {
+		panic(fmt.Sprintf("sim: embedded tuning.json: %v", err))
+	}
+	if err := t.Validate(); err != nil {
+		panic(fmt.Sprintf("sim: embedded tuning.json: %v", err))
+	}
+	return t
+}
+
+// ParseTuning applies the values in data on top of the defaults and validates
+// the result.
+func ParseTuning(data []byte) (Tuning, error) {
+	t := DefaultTuning()
+	if err := decodeStrict(data, &t); err != nil {
+		return t, err
+	}
+	if err := t.Validate(); err != nil {
+		return t, err
+	}
+	return t, nil
+}
+
+// LoadTuning reads a tuning file with ParseTuning.
+func LoadTuning(path string) (Tuning, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return DefaultTuning(), fmt.Errorf("failed to read tuning file: %w", err)
+	}
+
+	t, err := ParseTuning(data)
+	if err != nil {
+		return t, fmt.Errorf("%s: %w", path, err)
+	}
+	return t, nil
+}
+
+// Validate reports every value that is out of range.
+func (t Tuning) Validate() error {
+	var errs []error
+	positive := func(name string, v float64) {
+		if v <= 0 {
+			errs = append(errs, fmt.Errorf("%s must be greater than 0, got %v", name, v))
+		}
+	}
+	atLeast := func(name string, v, least int) {
+		if v < least {
+			errs = append(errs, fmt.Errorf("%s must be at least %d, got %d", name, least, v))
+		}
+	}
+	duration := func(name string, d Duration) {
+		if time.Duration(d) < time.Second/TicksPerSecond {
+			errs = append(errs, fmt.Errorf("%s must be at least one tick (%v), got %v", name, time.Second/TicksPerSecond, time.Duration(d)))
+		}
+	}
+
+	p := t.Player
+	positive("player.max_acceleration", p.MaxAcceleration)
+	positive("player.rotation_per_second", p.RotationPerSecond)
+	positive("player.reverse_speed", p.ReverseSpeed)
+	duration("player.shoot_cooldown", p.ShootCoolDown)
+	duration("player.burst_cooldown", p.BurstCoolDown)
+	atLeast("player.max_shots_per_burst", p.MaxShotsPerBurst, 1)
+	positive("player.laser_speed_per_second", p.LaserSpeedPerSecond)
+	atLeast("player.lives", p.Lives, 1)
+	atLeast("player.max_lives", p.MaxLives, p.Lives)
+	atLeast("player.shields", p.Shields, 0)
+	duration("player.shield_duration", p.ShieldDuration)
+	duration("player.hyperspace_cooldown", p.HyperSpaceCooldown)
+	duration("player.drift_time", p.DriftTime)
+	duration("player.dying_frame_time", p.DyingFrameTime)
+
+	m := t.Meteors
+	positive("meteors.base_velocity", m.BaseVelocity)
+	if m.SpeedUpAmount < 0 {
+		errs = append(errs, fmt.Errorf("meteors.speed_up_amount must not be negative, got %v", m.SpeedUpAmount))
+	}
+	duration("meteors.speed_up_time", m.SpeedUpTime)
+	duration("meteors.spawn_time", m.SpawnTime)
+	duration("meteors.explosion_frame_time", m.ExplosionFrameTime)
+	atLeast("meteors.max_small_from_large", m.MaxSmallFromLarge, 0)
+
+	a := t.Aliens
+	positive("aliens.base_velocity", a.BaseVelocity)
+	duration("aliens.spawn_time", a.SpawnTime)
+	duration("aliens.attack_time", a.AttackTime)
+	positive("aliens.laser_speed_per_second", a.LaserSpeedPerSecond)
+
+	return errors.Join(errs...)
+}
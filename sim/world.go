@@ -0,0 +1,560 @@
+// Package sim holds the Go Asteroids game rules. It has no dependency on a
+// window, renderer or audio device: a World is advanced one tick at a time
+// from an Input snapshot and reports what happened as a list of Events.
+package sim
+
+import (
+	"maps"
+	"math"
+	"math/rand/v2"
+	"slices"
+
+	"github.com/solarlune/resolv"
+)
+
+type Phase int
+
+const (
+	PhasePlaying Phase = iota
+	PhaseLevelComplete
+	PhaseGameOver
+)
+
+type World struct {
+	Player            *Player
+	Meteors           map[int]*Meteor
+	Lasers            map[int]*Laser
+	Aliens            map[int]*Alien
+	AlienLasers       map[int]*AlienLaser
+	Score             int
+	CurrentLevel      int
+	Phase             Phase
+	Tick              int
+	Seed              uint64
+	tuning            Tuning
+	rules             Rules
+	level             Level
+	rng               *rand.Rand
+	pcg               *rand.PCG
+	baseVelocity      float64
+	meteorCount       int
+	meteorsSpawnTimer *Timer
+	largeSpawned      int
+	smallSpawned      int
+	velocityTimer     *Timer
+	space             *resolv.Space
+	laserCount        int
+	alienAttackTimer  *Timer
+	alienCount        int
+	alienLaserCount   int
+	alienSpawnTimer   *Timer
+	prevInput         Input
+	events            []Event
+}
+
+// NewWorld creates a world whose every random decision is drawn from seed, so
+// that the same seed, rules and inputs always play out the same game.
+func NewWorld(seed uint64, rules Rules) *World {
+	tuning := rules.Tuning
+	w := &World{
+		rules:             rules,
+		tuning:            tuning,
+		meteorsSpawnTimer: tuning.Meteors.SpawnTime.timer(),
+		baseVelocity:      tuning.Meteors.BaseVelocity,
+		velocityTimer:     tuning.Meteors.SpeedUpTime.timer(),
+		space:             resolv.NewSpace(PlayfieldWidth, PlayfieldHeight, 16, 16),
+		alienSpawnTimer:   tuning.Aliens.SpawnTime.timer(),
+	}
+	w.Reset(seed)
+	return w
+}
+
+// Step advances the world by one tick and returns the events raised during it.
+// The returned slice is only valid until the next call to Step.
+func (w *World) Step(in Input) []Event {
+	w.events = w.events[:0]
+	if w.Phase != PhasePlaying {
+		return w.events
+	}
+	w.Tick++
+
+	w.Player.Update(in, w.prevInput)
+	w.prevInput = in
+
+	w.isPlayerDying()
+
+	w.isPlayerDead()
+	if w.Phase == PhaseGameOver {
+		return w.events
+	}
+
+	w.spawnMeteors()
+
+	w.spawnAliens()
+
+	for _, a := range w.Aliens {
+		a.Update()
+	}
+
+	w.letAliensAttack()
+
+	for _, al := range w.AlienLasers {
+		al.Update(w.tuning.Aliens.LaserSpeedPerSecond)
+	}
+
+	for _, m := range w.Meteors {
+		m.Update()
+	}
+
+	for _, l := range w.Lasers {
+		l.Update(w.tuning.Player.LaserSpeedPerSecond)
+	}
+
+	w.speedUpMeteors()
+
+	w.isPlayerCollidingWithMeteor()
+
+	w.isMeteorHitByPlayerLaser()
+
+	w.isPlayerCollidingWithAlien()
+
+	w.isPlayerHitByAlienLaser()
+
+	w.isAlienHitByPlayerLaser()
+
+	w.updateExplosions()
+
+	w.isLevelComplete()
+
+	w.removeOffScreenAliens()
+
+	w.removeOffScreenLasers()
+
+	return w.events
+}
+
+// Tuning returns the values the world is balanced with.
+func (w *World) Tuning() Tuning {
+	return w.tuning
+}
+
+// Rules returns the tuning and levels the world plays by.
+func (w *World) Rules() Rules {
+	return w.rules
+}
+
+// Level returns the definition of the current level.
+func (w *World) Level() Level {
+	return w.level
+}
+
+// StartNextLevel resumes play after a PhaseLevelComplete pause with the
+// meteor quota for the new level.
+func (w *World) StartNextLevel() {
+	w.meteorCount = 0
+	w.largeSpawned = 0
+	w.smallSpawned = 0
+	for k, v := range w.Lasers {
+		delete(w.Lasers, k)
+		w.space.Remove(v.laserObj)
+	}
+	w.Phase = PhasePlaying
+}
+
+// Reset starts a brand new game from the starting level using seed.
+func (w *World) Reset(seed uint64) {
+	w.Seed = seed
+	w.pcg = rand.NewPCG(seed, seed)
+	w.rng = rand.New(w.pcg)
+	w.CurrentLevel = max(1, w.rules.StartLevel)
+	w.enterLevel()
+	w.Score = 0
+	w.Tick = 0
+	w.prevInput = Input{}
+	w.Phase = PhasePlaying
+	w.resetRound()
+}
+
+// resetRound clears the playfield and puts a fresh ship in the middle of it.
+func (w *World) resetRound() {
+	w.Player = NewPlayer(w)
+	w.Meteors = make(map[int]*Meteor)
+	w.meteorCount = 0
+	w.largeSpawned = 0
+	w.smallSpawned = 0
+	w.meteorsSpawnTimer.Reset()
+	w.Lasers = make(map[int]*Laser)
+	w.laserCount = 0
+	w.baseVelocity = w.level.MeteorVelocity
+	w.velocityTimer.Reset()
+	w.space.RemoveAll()
+	w.space.Add(w.Player.playerObj)
+	w.Aliens = make(map[int]*Alien)
+	w.AlienLasers = make(map[int]*AlienLaser)
+	w.alienCount = 0
+	w.alienLaserCount = 0
+}
+
+func (w *World) isPlayerCollidingWithAlien() {
+	for _, a := range w.Aliens {
+		if a.Exploding {
+			continue
+		}
+		if a.alienObj.IsIntersecting(w.Player.playerObj) {
+			if !w.Player.Shielded {
+				w.killPlayer()
+			} else {
+				w.hitShield(a.Position)
+			}
+		}
+	}
+}
+
+func (w *World) isPlayerHitByAlienLaser() {
+	for _, l := range w.AlienLasers {
+		if l.laserObj.IsIntersecting(w.Player.playerObj) {
+			if !w.Player.Shielded {
+				w.killPlayer()
+			} else {
+				w.hitShield(AlienLaserSize.center(l.Position))
+			}
+		}
+	}
+}
+
+func (w *World) killPlayer() {
+	if w.rules.GodMode {
+		return
+	}
+	if !w.Player.Dying && !w.Player.isDead {
+		p := w.Player
+		p.Explosion = NewAnimation(ExplosionFrames, w.tuning.Player.DyingFrameTime, false, p.die)
+		w.emit(Event{
+			Kind:     EventExplosion,
+			Position: w.Player.Center(),
+			Radius:   PlayerSize.W / 2,
+		})
+		w.emit(Event{Kind: EventShipDestroyed, Position: w.Player.Center()})
+	}
+	w.Player.Dying = true
+}
+
+// hitShield reports something striking the shield from pos.
+func (w *World) hitShield(pos Vector) {
+	w.emit(Event{Kind: EventShieldHit, Position: midpoint(w.Player.Center(), pos)})
+}
+
+func (w *World) isAlienHitByPlayerLaser() {
+	for _, ai := range sortedKeys(w.Aliens) {
+		a := w.Aliens[ai]
+		if a.Exploding {
+			continue
+		}
+		for _, i := range sortedKeys(w.Lasers) {
+			l := w.Lasers[i]
+			if a.alienObj.IsIntersecting(l.laserObj) {
+				delete(w.Lasers, i)
+				w.space.Remove(l.laserObj)
+				w.explodeAlien(ai, a)
+				w.Score += 50
+				w.emit(Event{
+					Kind:     EventExplosion,
+					Position: a.Position,
+					Movement: a.Movement,
+					Radius:   AlienSizes[a.Variant].W / 2,
+				})
+				break
+			}
+		}
+	}
+}
+
+func (w *World) letAliensAttack() {
+	if len(w.Aliens) > 0 {
+		w.alienAttackTimer.Update()
+
+		if w.alienAttackTimer.IsReady() {
+			w.alienAttackTimer.Reset()
+			for _, i := range sortedKeys(w.Aliens) {
+				a := w.Aliens[i]
+				if a.Exploding {
+					continue
+				}
+				size := AlienSizes[a.Variant]
+				halfW, halfH := size.Half()
+
+				var degreesRadian float64
+				if !a.IsIntelligent {
+					degreesRadian = w.rng.Float64() * (math.Pi * 2)
+				} else {
+					degreesRadian = math.Atan2(w.Player.Position.Y-a.Position.Y, w.Player.Position.X-a.Position.X)
+					degreesRadian = degreesRadian - math.Pi*-0.5
+				}
+
+				r := degreesRadian
+
+				offsetX := float64(int(size.W) - int(halfW))
+				offsetY := float64(int(size.H) - int(halfH))
+
+				spawnPos := Vector{
+					X: a.Position.X + halfW + math.Sin(r) - offsetX,
+					Y: a.Position.Y + halfH + math.Cos(r) - offsetY,
+				}
+
+				laser := NewAlienLaser(spawnPos, r)
+				w.alienLaserCount++
+				w.AlienLasers[w.alienLaserCount] = laser
+				w.emit(Event{Kind: EventAlienLaserFired, Position: spawnPos})
+			}
+		}
+	}
+}
+
+func isOffScreen(pos Vector) bool {
+	return pos.X > PlayfieldWidth+200 || pos.Y > PlayfieldHeight+200 || pos.X < -200 || pos.Y < -200
+}
+
+func (w *World) removeOffScreenLasers() {
+	for i, l := range w.Lasers {
+		if isOffScreen(l.Position) {
+			w.space.Remove(l.laserObj)
+			delete(w.Lasers, i)
+		}
+	}
+
+	for i, l := range w.AlienLasers {
+		if isOffScreen(l.Position) {
+			delete(w.AlienLasers, i)
+		}
+	}
+}
+
+func (w *World) spawnAliens() {
+	w.alienSpawnTimer.Update()
+	if len(w.Aliens) <= 3 {
+		if w.alienSpawnTimer.IsReady() {
+			w.alienSpawnTimer.Reset()
+			if w.rng.Float64() < w.level.AlienChance {
+				kind := w.level.AlienTypes[w.rng.IntN(len(w.level.AlienTypes))]
+				a := NewAlien(kind, w.tuning.Aliens.BaseVelocity, w)
+				w.space.Add(a.alienObj)
+				w.alienCount++
+				w.Aliens[w.alienCount] = a
+			}
+		}
+	}
+}
+
+func (w *World) removeOffScreenAliens() {
+	for i, a := range w.Aliens {
+		if isOffScreen(a.Position) {
+			w.space.Remove(a.alienObj)
+			delete(w.Aliens, i)
+		}
+	}
+}
+
+func (w *World) isLevelComplete() {
+	if w.largeSpawned >= w.level.LargeMeteors && w.smallSpawned >= w.level.SmallMeteors && len(w.Meteors) == 0 {
+		w.CurrentLevel++
+		w.enterLevel()
+
+		if w.level.ExtraLife && w.Player.LivesRemaining < w.tuning.Player.MaxLives {
+			w.Player.LivesRemaining++
+			w.emit(Event{Kind: EventExtraLife})
+		}
+		w.Score += w.level.BonusScore
+
+		w.Phase = PhaseLevelComplete
+		w.emit(Event{Kind: EventLevelComplete})
+	}
+}
+
+// enterLevel looks up the definition of the current level and applies its
+// pace to the meteors and aliens.
+func (w *World) enterLevel() {
+	w.level = w.rules.Levels.Level(w.CurrentLevel, w.tuning)
+	w.baseVelocity = w.level.MeteorVelocity
+	w.alienAttackTimer = w.level.AlienAttackTime.timer()
+}
+
+func (w *World) isPlayerDying() {
+	if w.Player.Dying {
+		w.Player.Explosion.Update()
+	}
+}
+
+func (w *World) isPlayerDead() {
+	if !w.Player.isDead {
+		return
+	}
+
+	livesRemaining := w.Player.LivesRemaining - 1
+	if livesRemaining == 0 {
+		w.Player.LivesRemaining = 0
+		w.Phase = PhaseGameOver
+		w.emit(Event{Kind: EventGameOver})
+		return
+	}
+
+	shieldsRemaining := w.Player.ShieldRemaining
+	w.resetRound()
+	w.Player.LivesRemaining = livesRemaining
+	w.Player.ShieldRemaining = shieldsRemaining
+	w.emit(Event{Kind: EventLifeLost})
+}
+
+func (w *World) isMeteorHitByPlayerLaser() {
+	for _, i := range sortedKeys(w.Meteors) {
+		m := w.Meteors[i]
+		if m.Exploding {
+			continue
+		}
+		for _, l := range w.Lasers {
+			if m.meteorObj.IsIntersecting(l.laserObj) {
+				w.explodeMeteor(i, m)
+				w.Score++
+				w.emit(Event{
+					Kind:     EventExplosion,
+					Position: m.Size().center(m.Position),
+					Movement: m.Movement,
+					Radius:   m.Size().W / 2,
+					Large:    !m.Small,
+				})
+
+				if !m.Small {
+					oldPos := m.Position
+
+					numToSpawn := w.rng.IntN(w.tuning.Meteors.MaxSmallFromLarge + 1)
+					for range numToSpawn {
+						meteor := NewSmallMeteor(w.rng, w.tuning.Meteors.BaseVelocity, len(w.Meteors)-1)
+						meteor.SetPosition(Vector{oldPos.X + float64(w.rng.IntN(100-50)+50), oldPos.Y + float64(w.rng.IntN(100-50)+50)})
+						w.space.Add(meteor.meteorObj)
+
+						w.meteorCount++
+						w.Meteors[w.meteorCount] = meteor
+					}
+				}
+				break
+			}
+		}
+	}
+}
+
+func (w *World) spawnMeteors() {
+	w.meteorsSpawnTimer.Update()
+	if w.meteorsSpawnTimer.IsReady() {
+		w.meteorsSpawnTimer.Reset()
+		if len(w.Meteors) >= w.level.LargeMeteors+w.level.SmallMeteors {
+			return
+		}
+
+		var m *Meteor
+		switch {
+		case w.largeSpawned < w.level.LargeMeteors:
+			m = NewMeteor(w.rng, w.baseVelocity, len(w.Meteors)-1)
+			w.largeSpawned++
+		case w.smallSpawned < w.level.SmallMeteors:
+			m = NewSmallMeteor(w.rng, w.baseVelocity, len(w.Meteors)-1)
+			w.smallSpawned++
+		default:
+			return
+		}
+		w.space.Add(m.meteorObj)
+		w.meteorCount++
+		w.Meteors[w.meteorCount] = m
+	}
+}
+
+func (w *World) speedUpMeteors() {
+	w.velocityTimer.Update()
+	if w.velocityTimer.IsReady() {
+		w.velocityTimer.Reset()
+		w.baseVelocity += w.level.MeteorSpeedUp
+	}
+}
+
+func (w *World) isPlayerCollidingWithMeteor() {
+	for _, i := range sortedKeys(w.Meteors) {
+		m := w.Meteors[i]
+		if m.Exploding {
+			continue
+		}
+		if m.meteorObj.IsIntersecting(w.Player.playerObj) {
+			if !w.Player.Shielded {
+				w.killPlayer()
+				break
+			} else {
+				w.bounceMeteor(m)
+				w.hitShield(m.Size().center(m.Position))
+			}
+		}
+	}
+}
+
+func (w *World) bounceMeteor(m *Meteor) {
+	direction := Vector{
+		X: (PlayfieldWidth/2 - m.Position.X) * -1,
+		Y: (PlayfieldHeight/2 - m.Position.Y) * -1,
+	}
+	normalizeDirection := direction.Normalize()
+	velocity := w.baseVelocity
+
+	movement := Vector{
+		X: normalizeDirection.X * velocity,
+		Y: normalizeDirection.Y * velocity,
+	}
+
+	m.Movement = movement
+}
+
+// explodeMeteor starts the meteor exploding. It is removed once its
+// explosion has played.
+func (w *World) explodeMeteor(id int, m *Meteor) {
+	m.Exploding = true
+	m.Explosion = NewAnimation(ExplosionFrames, w.tuning.Meteors.ExplosionFrameTime, false, w.removeMeteor(id, m))
+}
+
+func (w *World) removeMeteor(id int, m *Meteor) func() {
+	return func() {
+		delete(w.Meteors, id)
+		w.space.Remove(m.meteorObj)
+	}
+}
+
+// explodeAlien starts the alien exploding. It is removed once its explosion
+// has played.
+func (w *World) explodeAlien(id int, a *Alien) {
+	a.Exploding = true
+	a.Explosion = NewAnimation(ExplosionFrames, w.tuning.Meteors.ExplosionFrameTime, false, w.removeAlien(id, a))
+}
+
+func (w *World) removeAlien(id int, a *Alien) func() {
+	return func() {
+		delete(w.Aliens, id)
+		w.space.Remove(a.alienObj)
+	}
+}
+
+// updateExplosions moves every explosion on by a tick, removing whatever
+// has finished exploding.
+func (w *World) updateExplosions() {
+	for _, i := range sortedKeys(w.Meteors) {
+		if m := w.Meteors[i]; m.Exploding {
+			m.Explosion.Update()
+		}
+	}
+
+	for _, i := range sortedKeys(w.Aliens) {
+		if a := w.Aliens[i]; a.Exploding {
+			a.Explosion.Update()
+		}
+	}
+}
+
+// sortedKeys returns the keys of m in ascending order. Entities are kept in
+// maps, whose iteration order is random, so anything that draws from the RNG
+// or depends on which entity is handled first must walk them in this order.
+func sortedKeys[V any](m map[int]V) []int {
+	return slices.Sorted(maps.Keys(m))
+}
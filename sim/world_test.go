@@ -0,0 +1,88 @@
+package sim
+
+import (
+	"math/rand/v2"
+	"reflect"
+	"testing"
+)
+
+const testSeed = 42
+
+// testTicks is long enough for the scripted inputs to clear a level, meet
+// aliens and lose every life.
+const testTicks = 5000
+
+// scriptedInputs returns n ticks of input that flies the ship around and
+// fires, the same for every call with the same seed.
+func scriptedInputs(seed uint64, n int) []Input {
+	rng := rand.New(rand.NewPCG(seed, 1))
+	inputs := make([]Input, n)
+	var held Input
+	for i := range inputs {
+		if i%20 == 0 {
+			held = Input{
+				RotateLeft:  rng.IntN(3) == 0,
+				RotateRight: rng.IntN(3) == 0,
+				Thrust:      rng.IntN(2) == 0,
+				Shield:      rng.IntN(40) == 0,
+				HyperSpace:  rng.IntN(60) == 0,
+			}
+		}
+		inputs[i] = held
+		inputs[i].Fire = rng.IntN(4) == 0
+	}
+	return inputs
+}
+
+// step steps w with in, starting the next level first if the last one is
+// complete, as the game and Replay.Play do. It returns a copy of the events,
+// nil if there were none, since the world reuses its slice.
+func step(w *World, in Input) []Event {
+	if w.Phase == PhaseLevelComplete {
+		w.StartNextLevel()
+	}
+	return append([]Event(nil), w.Step(in)...)
+}
+
+// assertSameWorld fails t if a and b have come to different states.
+func assertSameWorld(t *testing.T, a, b *World) {
+	t.Helper()
+	if a.Tick != b.Tick || a.Score != b.Score || a.CurrentLevel != b.CurrentLevel || a.Phase != b.Phase {
+		t.Fatalf("worlds differ: tick %d/%d, score %d/%d, level %d/%d, phase %d/%d",
+			a.Tick, b.Tick, a.Score, b.Score, a.CurrentLevel, b.CurrentLevel, a.Phase, b.Phase)
+	}
+
+	sa, err := a.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sb, err := b.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(sa, sb) {
+		t.Fatal("world snapshots differ")
+	}
+}
+
+func TestWorldIsDeterministic(t *testing.T) {
+	a := NewWorld(testSeed, DefaultRules())
+	b := NewWorld(testSeed, DefaultRules())
+
+	events := 0
+	for tick, in := range scriptedInputs(testSeed, testTicks) {
+		ea, eb := step(a, in), step(b, in)
+		if !reflect.DeepEqual(ea, eb) {
+			t.Fatalf("tick %d: events differ:\n%+v\n%+v", tick, ea, eb)
+		}
+		if a.Score != b.Score {
+			t.Fatalf("tick %d: score %d, want %d", tick, b.Score, a.Score)
+		}
+		events += len(ea)
+	}
+
+	if events == 0 {
+		t.Fatal("no events were raised, so nothing was compared")
+	}
+	assertSameWorld(t, a, b)
+}